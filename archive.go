@@ -0,0 +1,136 @@
+package ueloghandler
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+var zipMagic = []byte{'P', 'K', 0x03, 0x04}
+
+type compressionType int
+
+const (
+	compressionNone compressionType = iota
+	compressionGzip
+	compressionZstd
+)
+
+func detectCompression(header []byte) compressionType {
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		return compressionGzip
+	case bytes.HasPrefix(header, zstdMagic):
+		return compressionZstd
+	default:
+		return compressionNone
+	}
+}
+
+// NewDecompressReader Get reader decompressing gzip or zstd stream
+//
+// The compression format is detected from the magic number. Uncompressed stream is returned as it is.
+// Close the returned reader to release decoder resources.
+func NewDecompressReader(r io.Reader) (io.ReadCloser, error) {
+	bufReader := bufio.NewReader(r)
+	header, err := bufReader.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	switch detectCompression(header) {
+	case compressionGzip:
+		return gzip.NewReader(bufReader)
+	case compressionZstd:
+		decoder, err := zstd.NewReader(bufReader)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return io.NopCloser(bufReader), nil
+	}
+}
+
+// ReadLogFile Read all logs of the file and pass them to handle
+//
+// gzip and zstd compressed files are decompressed.
+// For zip archives, logs of all files in the archive are read. Compressed files in the archive are also decompressed.
+// Log.Source is the file path, or the file path and the member name joined with "/" for zip archives.
+func ReadLogFile(filePath string, handle func(Log) error) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	header := make([]byte, len(zipMagic))
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return err
+	}
+
+	if bytes.Equal(header[:n], zipMagic) {
+		fstat, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		return readZipLogs(file, fstat.Size(), filePath, handle)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return readCompressedLogs(file, filePath, handle)
+}
+
+func readZipLogs(r io.ReaderAt, size int64, filePath string, handle func(Log) error) error {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range archive.File {
+		if member.FileInfo().IsDir() {
+			continue
+		}
+
+		err := func() error {
+			memberReader, err := member.Open()
+			if err != nil {
+				return err
+			}
+			defer memberReader.Close()
+
+			return readCompressedLogs(memberReader, filePath+"/"+member.Name, handle)
+		}()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readCompressedLogs(r io.Reader, source string, handle func(Log) error) error {
+	decompressReader, err := NewDecompressReader(r)
+	if err != nil {
+		return err
+	}
+	defer decompressReader.Close()
+
+	reader := NewReader(decompressReader)
+	reader.SetSource(source)
+	for reader.Next() {
+		if err := handle(reader.Log()); err != nil {
+			return err
+		}
+	}
+	return reader.Err()
+}
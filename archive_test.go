@@ -0,0 +1,189 @@
+package ueloghandler_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+const archiveTestLog = `Log file open, 05/02/22 13:01:53
+[2022.05.02-04.01.58:862][970]LogHttp: Warning: warningline1
+warningline2
+[2022.05.02-04.01.58:905][970]Log file closed, 05/02/22 13:01:58
+`
+
+var archiveTestWantLogs = []string{
+	"Log file open, 05/02/22 13:01:53\n",
+	"[2022.05.02-04.01.58:862][970]LogHttp: Warning: warningline1\nwarningline2\n",
+	"[2022.05.02-04.01.58:905][970]Log file closed, 05/02/22 13:01:58\n",
+}
+
+func gzipData(t *testing.T, data string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(data))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func zstdData(t *testing.T, data string) []byte {
+	var buf bytes.Buffer
+	w, err := zstd.NewWriter(&buf)
+	assert.NoError(t, err)
+	_, err = w.Write([]byte(data))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func zipData(t *testing.T, files map[string][]byte, names []string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range names {
+		f, err := w.Create(name)
+		assert.NoError(t, err)
+		_, err = f.Write(files[name])
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestNewDecompressReader(t *testing.T) {
+	testCases := map[string][]byte{
+		"None": []byte(archiveTestLog),
+		"Gzip": gzipData(t, archiveTestLog),
+		"Zstd": zstdData(t, archiveTestLog),
+	}
+
+	for name, data := range testCases {
+		data := data
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			r, err := ueloghandler.NewDecompressReader(bytes.NewReader(data))
+			if !assert.NoError(err) {
+				return
+			}
+			defer r.Close()
+
+			decompressed, err := io.ReadAll(r)
+			assert.NoError(err)
+			assert.Equal(archiveTestLog, string(decompressed))
+		})
+	}
+}
+
+func TestReadLogFile(t *testing.T) {
+	dir, err := os.MkdirTemp("", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	type testCase struct {
+		fileName string
+		data     []byte
+		members  []string
+	}
+	testCases := []testCase{
+		{fileName: "ue.log", data: []byte(archiveTestLog), members: []string{""}},
+		{fileName: "ue.log.gz", data: gzipData(t, archiveTestLog), members: []string{""}},
+		{fileName: "ue.log.zst", data: zstdData(t, archiveTestLog), members: []string{""}},
+		{
+			fileName: "crash.zip",
+			data: zipData(t, map[string][]byte{
+				"Logs/":          nil,
+				"Logs/ue.log":    []byte(archiveTestLog),
+				"Logs/ue.log.gz": gzipData(t, archiveTestLog),
+			}, []string{"Logs/", "Logs/ue.log", "Logs/ue.log.gz"}),
+			members: []string{"/Logs/ue.log", "/Logs/ue.log.gz"},
+		},
+	}
+
+	for i := range testCases {
+		testCase := testCases[i]
+		t.Run(testCase.fileName, func(t *testing.T) {
+			assert := assert.New(t)
+
+			filePath := filepath.Join(dir, testCase.fileName)
+			assert.NoError(os.WriteFile(filePath, testCase.data, 0666))
+
+			wantLogs := []ueloghandler.Log{}
+			for _, member := range testCase.members {
+				for _, logStr := range archiveTestWantLogs {
+					log := ueloghandler.NewLog(logStr)
+					log.Source = filePath + member
+					wantLogs = append(wantLogs, log)
+				}
+			}
+
+			logs := []ueloghandler.Log{}
+			err := ueloghandler.ReadLogFile(filePath, func(log ueloghandler.Log) error {
+				logs = append(logs, log)
+				return nil
+			})
+			assert.NoError(err)
+			assert.Equal(wantLogs, logs)
+		})
+	}
+}
+
+func TestFileNotifierCompressed(t *testing.T) {
+	assert := assert.New(t)
+
+	tmpFile, err := NewTestLogFile()
+	if !assert.NoError(err) {
+		return
+	}
+	defer tmpFile.Close()
+
+	notifier := ueloghandler.NewFileNotifier(tmpFile.Name(), time.Millisecond)
+	receiveLogs := []string{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for log := range notifier.Logs() {
+			receiveLogs = append(receiveLogs, log)
+		}
+	}()
+
+	// Appended gzip members are read as a stream
+	lines := strings.SplitAfter(archiveTestLog, "\n")
+	assert.NoError(os.WriteFile(tmpFile.Name(), gzipData(t, strings.Join(lines[:2], "")), 0666))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	subscribeEnd := make(chan struct{})
+	go func() {
+		defer close(subscribeEnd)
+		assert.NoError(notifier.Subscribe(ctx))
+	}()
+
+	time.Sleep(time.Millisecond * 100)
+	f, err := os.OpenFile(tmpFile.Name(), os.O_WRONLY|os.O_APPEND, 0666)
+	if assert.NoError(err) {
+		_, err = f.Write(gzipData(t, strings.Join(lines[2:], "")))
+		assert.NoError(err)
+		f.Close()
+	}
+	time.Sleep(time.Millisecond * 100)
+
+	cancel()
+	<-subscribeEnd
+	assert.NoError(notifier.Flush())
+	close(notifier.Logs())
+	<-done
+
+	assert.Equal(archiveTestWantLogs, receiveLogs)
+}
@@ -4,6 +4,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/y-akahori-ramen/ueLogHandler/gen"
 )
@@ -13,18 +15,43 @@ func main() {
 	goOut := flag.String("go-out", "", "Path to generated go file")
 	goPackageName := flag.String("go-package", "", "Package name of generated go file")
 	cppOut := flag.String("cpp-out", "", "Path to generated cpp file")
+	cppSourceOut := flag.String("cpp-source-out", "", "Path to generated cpp source file defining log categories. Header is included by the file name of -cpp-out")
 	cppNamespace := flag.String("cpp-namespace", "", "Namespace name of generated cpp file")
+	cppExternalCategories := flag.String("cpp-external-categories", "", "Comma separated log categories declared out of generated cpp files. Engine categories such as LogTemp are always external")
+	blueprintOut := flag.String("blueprint-out", "", "Path to generated header of Blueprint function library. Class is named after the file name")
+	blueprintSourceOut := flag.String("blueprint-source-out", "", "Path to generated cpp source file of Blueprint function library")
+	blueprintAPI := flag.String("blueprint-api", "", "API macro of module exporting Blueprint function library. e.g. MYGAME_API")
+	released := flag.String("released", "", "Path to structure schema file of released game builds. Generation fails if changes break handling their logs")
 
 	flag.Parse()
 
-	structureInfoList, err := readSchema(*src)
+	var externalCategories []string
+	if *cppExternalCategories != "" {
+		externalCategories = strings.Split(*cppExternalCategories, ",")
+	}
+
+	schema, err := readSchema(*src)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 
+	if *released != "" {
+		releasedSchema, err := readSchema(*released)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		err = gen.CheckSchemaCompatibility(releasedSchema, schema)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
 	if *goOut != "" {
-		err = generateGo(structureInfoList, *goOut, *goPackageName)
+		err = generateGo(schema, *goOut, *goPackageName)
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
@@ -32,7 +59,38 @@ func main() {
 	}
 
 	if *cppOut != "" {
-		err = generateCpp(structureInfoList, *cppOut, *cppNamespace)
+		err = generateCpp(schema, *cppOut, *cppNamespace, externalCategories)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	if *cppSourceOut != "" {
+		if *cppOut == "" {
+			fmt.Println("-cpp-source-out requires -cpp-out")
+			os.Exit(1)
+		}
+
+		err = generateCppSource(schema, *cppSourceOut, *cppOut, externalCategories)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	if *blueprintOut != "" || *blueprintSourceOut != "" {
+		if *blueprintOut == "" || *blueprintSourceOut == "" || *cppOut == "" {
+			fmt.Println("-blueprint-out and -blueprint-source-out require each other and -cpp-out")
+			os.Exit(1)
+		}
+
+		config := gen.BlueprintConfig{
+			HeaderName: filepath.Base(*blueprintOut),
+			APIMacro:   *blueprintAPI,
+			Cpp:        gen.CppConfig{Namespace: *cppNamespace, HeaderName: filepath.Base(*cppOut), ExternalCategories: externalCategories},
+		}
+		err = generateBlueprint(schema, config, *blueprintOut, *blueprintSourceOut)
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
@@ -42,36 +100,69 @@ func main() {
 	os.Exit(0)
 }
 
-func readSchema(schemaFilePath string) (gen.StructureInfoList, error) {
+func readSchema(schemaFilePath string) (gen.Schema, error) {
 	schemaFile, err := os.Open(schemaFilePath)
 	if err != nil {
-		return nil, err
+		return gen.Schema{}, err
 	}
 	defer schemaFile.Close()
 
-	return gen.ReadStructureInfoYAML(schemaFile)
+	return gen.ReadSchemaYAML(schemaFile)
 }
 
-func generateGo(structureInfoList gen.StructureInfoList, goOut, goPackageName string) error {
+func generateGo(schema gen.Schema, goOut, goPackageName string) error {
 	genFile, err := os.Create(goOut)
 	if err != nil {
 		return err
 	}
 	defer genFile.Close()
 
-	err = gen.GenGoFile(genFile, goPackageName, structureInfoList)
+	err = gen.GenGoSchemaFile(genFile, goPackageName, schema)
 
 	return err
 }
 
-func generateCpp(structureInfoList gen.StructureInfoList, cppOut, cppNamespace string) error {
+func generateCpp(schema gen.Schema, cppOut, cppNamespace string, externalCategories []string) error {
 	genFile, err := os.Create(cppOut)
 	if err != nil {
 		return err
 	}
 	defer genFile.Close()
 
-	err = gen.GenCppFile(genFile, cppNamespace, structureInfoList)
+	err = gen.GenCppSchemaFile(genFile, gen.CppConfig{Namespace: cppNamespace, HeaderName: filepath.Base(cppOut), ExternalCategories: externalCategories}, schema)
+
+	return err
+}
+
+func generateCppSource(schema gen.Schema, cppSourceOut, cppOut string, externalCategories []string) error {
+	genFile, err := os.Create(cppSourceOut)
+	if err != nil {
+		return err
+	}
+	defer genFile.Close()
+
+	err = gen.GenCppSourceFile(genFile, gen.CppConfig{HeaderName: filepath.Base(cppOut), ExternalCategories: externalCategories}, schema)
 
 	return err
 }
+
+func generateBlueprint(schema gen.Schema, config gen.BlueprintConfig, blueprintOut, blueprintSourceOut string) error {
+	headerFile, err := os.Create(blueprintOut)
+	if err != nil {
+		return err
+	}
+	defer headerFile.Close()
+
+	err = gen.GenBlueprintHeaderFile(headerFile, config, schema)
+	if err != nil {
+		return err
+	}
+
+	sourceFile, err := os.Create(blueprintSourceOut)
+	if err != nil {
+		return err
+	}
+	defer sourceFile.Close()
+
+	return gen.GenBlueprintSourceFile(sourceFile, config, schema)
+}
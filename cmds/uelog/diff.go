@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+func readReport(filePath string, filter ueloghandler.LogFilter) (ueloghandler.StatsReport, error) {
+	stats := ueloghandler.NewStatsLogHandler()
+	var handler ueloghandler.LogHandler = stats
+	if filter != nil {
+		handler = ueloghandler.NewFilterLogHandler(filter, stats)
+	}
+
+	err := ueloghandler.ReadLogFile(filePath, handler.HandleLog)
+	if err != nil {
+		return ueloghandler.StatsReport{}, err
+	}
+	return stats.Report(-1), nil
+}
+
+func writeDiffText(w io.Writer, diff ueloghandler.LogDiff) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	sections := []struct {
+		title string
+		diffs []ueloghandler.MessageDiff
+	}{
+		{"New", diff.New},
+		{"Resolved", diff.Resolved},
+		{"Changed", diff.Changed},
+	}
+	for _, section := range sections {
+		fmt.Fprintf(tw, "== %s (%d) ==\n", section.title, len(section.diffs))
+		category := "\x00"
+		for _, d := range section.diffs {
+			if d.Category != category {
+				category = d.Category
+				fmt.Fprintf(tw, "[%s]\n", category)
+			}
+			fmt.Fprintf(tw, "  %d -> %d\t%s\t%s\n", d.BaseCount, d.TargetCount, d.Verbosity, firstLine(d.Message))
+		}
+		fmt.Fprintln(tw, "")
+	}
+
+	return tw.Flush()
+}
+
+func runDiff(args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: uelog diff [flags] <base file> <target file>")
+		fmt.Fprintln(flags.Output(), "")
+		fmt.Fprintln(flags.Output(), "Report new, resolved and changed-frequency warnings and errors per category.")
+		fmt.Fprintln(flags.Output(), "Messages are compared after normalizing time, frame, addresses, GUIDs, paths and numbers.")
+		fmt.Fprintln(flags.Output(), "")
+		flags.PrintDefaults()
+	}
+
+	var filterFlags filterFlags
+	filterFlags.register(flags)
+	format := flags.String("format", "text", "Output format: text or json")
+	minChange := flags.Float64("min-change", 0, "Report changed messages only when count changes by this ratio or more. e.g. 0.5 for 50%")
+	flags.Parse(args)
+
+	// Checked before reading files
+	switch *format {
+	case "text", "json":
+	default:
+		return fmt.Errorf("invalid format: %s", *format)
+	}
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return errors.New("two log files are required")
+	}
+
+	filter, err := filterFlags.filter()
+	if err != nil {
+		return err
+	}
+
+	base, err := readReport(flags.Arg(0), filter)
+	if err != nil {
+		return err
+	}
+	target, err := readReport(flags.Arg(1), filter)
+	if err != nil {
+		return err
+	}
+
+	diff := ueloghandler.DiffStats(base, target)
+	changed := []ueloghandler.MessageDiff{}
+	for _, d := range diff.Changed {
+		ratio := float64(d.TargetCount-d.BaseCount) / float64(d.BaseCount)
+		if ratio >= *minChange || -ratio >= *minChange {
+			changed = append(changed, d)
+		}
+	}
+	diff.Changed = changed
+
+	switch *format {
+	case "text":
+		return writeDiffText(os.Stdout, diff)
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diff)
+	default:
+		return fmt.Errorf("invalid format: %s", *format)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+// filterFlags Flags to filter logs shared by commands
+type filterFlags struct {
+	categories string
+	verbosity  string
+	pattern    string
+	since      string
+	until      string
+	localTime  bool
+}
+
+func (f *filterFlags) register(flags *flag.FlagSet) {
+	flags.StringVar(&f.categories, "category", "", "Comma separated categories to output. e.g. LogTemp,LogNet")
+	flags.StringVar(&f.verbosity, "verbosity", "", "Output logs equal to or more severe than verbosity. e.g. Warning")
+	flags.StringVar(&f.pattern, "grep", "", "Output logs matching regular expression")
+	flags.StringVar(&f.since, "since", "", "Output logs at or after time. Format: 2006.01.02-15.04.05 or RFC3339")
+	flags.StringVar(&f.until, "until", "", "Output logs before time. Format: 2006.01.02-15.04.05 or RFC3339")
+	flags.BoolVar(&f.localTime, "local-time", false, "Log time is local time. Use this when the engine is run with -LocalLogTimes")
+}
+
+func (f *filterFlags) location() *time.Location {
+	if f.localTime {
+		return time.Local
+	}
+	return time.UTC
+}
+
+// filter Create filter from flags. nil is returned if no filter is specified.
+func (f *filterFlags) filter() (ueloghandler.LogFilter, error) {
+	filters := []ueloghandler.LogFilter{}
+
+	if f.categories != "" {
+		filters = append(filters, ueloghandler.CategoryFilter(strings.Split(f.categories, ",")...))
+	}
+
+	if f.verbosity != "" {
+		verbosityFilter, err := ueloghandler.VerbosityFilter(f.verbosity)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, verbosityFilter)
+	}
+
+	if f.pattern != "" {
+		pattern, err := regexp.Compile(f.pattern)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, ueloghandler.PatternFilter(pattern))
+	}
+
+	if f.since != "" || f.until != "" {
+		since, err := parseTimeFlag(f.since, f.location())
+		if err != nil {
+			return nil, err
+		}
+		until, err := parseTimeFlag(f.until, f.location())
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, ueloghandler.TimeRangeFilter(since, until, f.location()))
+	}
+
+	if len(filters) == 0 {
+		return nil, nil
+	}
+	return ueloghandler.AllFilter(filters...), nil
+}
+
+func parseTimeFlag(value string, loc *time.Location) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006.01.02-15.04.05", value, loc); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time: %s", value)
+}
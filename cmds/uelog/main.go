@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name        string
+	description string
+	run         func(args []string) error
+}
+
+var commands = []command{
+	{name: "print", description: "Print filtered logs. Follow log file with -f", run: runPrint},
+	{name: "stats", description: "Report summary of logs as text, JSON or HTML", run: runStats},
+	{name: "diff", description: "Compare warnings and errors of two logs", run: runDiff},
+	{name: "timing", description: "Report startup and map load timings", run: runTiming},
+}
+
+func usage() {
+	fmt.Fprintln(flag.CommandLine.Output(), "Usage: uelog <command> [flags] <file>...")
+	fmt.Fprintln(flag.CommandLine.Output(), "")
+	fmt.Fprintln(flag.CommandLine.Output(), "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(flag.CommandLine.Output(), "  %-8s %s\n", c.name, c.description)
+	}
+	fmt.Fprintln(flag.CommandLine.Output(), "")
+	fmt.Fprintln(flag.CommandLine.Output(), "Run 'uelog <command> -h' for flags of command.")
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := flag.Arg(0)
+	for _, c := range commands {
+		if c.name == name {
+			err := c.run(flag.Args()[1:])
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "uelog: unknown command %s\n", name)
+	usage()
+	os.Exit(2)
+}
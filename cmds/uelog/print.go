@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+const (
+	colorReset  = "\x1b[0m"
+	colorRed    = "\x1b[31m"
+	colorYellow = "\x1b[33m"
+	colorCyan   = "\x1b[36m"
+	colorGray   = "\x1b[90m"
+)
+
+type printer struct {
+	w     io.Writer
+	json  bool
+	color bool
+}
+
+func (p *printer) HandleLog(log ueloghandler.Log) error {
+	if p.json {
+		return json.NewEncoder(p.w).Encode(log)
+	}
+
+	logStr := log.Log
+	if !strings.HasSuffix(logStr, "\n") {
+		logStr += "\n"
+	}
+
+	if !p.color {
+		_, err := io.WriteString(p.w, logStr)
+		return err
+	}
+
+	color := ""
+	switch ueloghandler.VerbosityLevel(log.Verbosity) {
+	case ueloghandler.VerbosityLevelFatal, ueloghandler.VerbosityLevelError:
+		color = colorRed
+	case ueloghandler.VerbosityLevelWarning:
+		color = colorYellow
+	case ueloghandler.VerbosityLevelDisplay:
+		color = colorCyan
+	case ueloghandler.VerbosityLevelVerbose, ueloghandler.VerbosityLevelVeryVerbose:
+		color = colorGray
+	}
+	if color == "" {
+		_, err := io.WriteString(p.w, logStr)
+		return err
+	}
+
+	_, err := fmt.Fprintf(p.w, "%s%s%s\n", color, strings.TrimSuffix(logStr, "\n"), colorReset)
+	return err
+}
+
+func isTerminal(f *os.File) bool {
+	fstat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fstat.Mode()&os.ModeCharDevice != 0
+}
+
+func runPrint(args []string) error {
+	flags := flag.NewFlagSet("print", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: uelog print [flags] <file>...")
+		fmt.Fprintln(flags.Output(), "")
+		fmt.Fprintln(flags.Output(), "Print Unreal Engine logs. gzip, zstd and zip archived logs are also supported.")
+		fmt.Fprintln(flags.Output(), "")
+		flags.PrintDefaults()
+	}
+
+	var filterFlags filterFlags
+	filterFlags.register(flags)
+	follow := flags.Bool("f", false, "Follow log file. Following continues when the file is recreated by engine restart")
+	interval := flags.Duration("interval", time.Millisecond*500, "Interval to check file update in follow mode")
+	jsonOutput := flags.Bool("json", false, "Output logs as JSON lines")
+	color := flags.String("color", "auto", "Colorize output: auto, always or never")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return errors.New("no log file")
+	}
+	if *follow && flags.NArg() != 1 {
+		return errors.New("only one file can be followed")
+	}
+
+	p := &printer{w: os.Stdout, json: *jsonOutput}
+	switch *color {
+	case "auto":
+		p.color = !p.json && isTerminal(os.Stdout)
+	case "always":
+		p.color = !p.json
+	case "never":
+	default:
+		return fmt.Errorf("invalid color: %s", *color)
+	}
+
+	filter, err := filterFlags.filter()
+	if err != nil {
+		return err
+	}
+	var handler ueloghandler.LogHandler = p
+	if filter != nil {
+		handler = ueloghandler.NewFilterLogHandler(filter, p)
+	}
+
+	if *follow {
+		ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer cancel()
+		return followFile(ctx, flags.Arg(0), *interval, handler)
+	}
+
+	for _, filePath := range flags.Args() {
+		err := ueloghandler.ReadLogFile(filePath, handler.HandleLog)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// followFile Watch log file until ctx is done
+//
+// Unreal Engine renames the log file to backup and creates new one on start.
+// Watching restarts when the file is created again.
+func followFile(ctx context.Context, filePath string, interval time.Duration, handler ueloghandler.LogHandler) error {
+	watcher := ueloghandler.NewWatcher()
+	watcher.AddLogHandler(handler)
+
+	for {
+		err := waitFileExists(ctx, filePath, interval)
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		notifier := ueloghandler.NewFileNotifier(filePath, interval)
+		notifier.SetReadExisting(true)
+		err = watcher.Watch(ctx, notifier)
+		if !errors.Is(err, ueloghandler.ErrFileRemoved) {
+			return err
+		}
+	}
+}
+
+func waitFileExists(ctx context.Context, filePath string, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := os.Stat(filePath)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
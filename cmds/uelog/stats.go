@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+type fileReport struct {
+	Source string
+	ueloghandler.StatsReport
+}
+
+var statsHTMLTemplate = template.Must(template.New("stats").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Unreal Engine log report</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 2px 8px; text-align: left; }
+.Error, .Fatal { color: #c00; }
+.Warning { color: #a60; }
+pre { margin: 0; }
+</style>
+</head>
+<body>
+{{range .}}
+<h1>{{.Source}}</h1>
+<table>
+<tr><th>Logs</th><td>{{.TotalLogs}}</td></tr>
+<tr><th>First</th><td>{{.FirstTime}} [{{.FirstFrame}}]</td></tr>
+<tr><th>Last</th><td>{{.LastTime}} [{{.LastFrame}}]</td></tr>
+<tr><th>Crashes</th><td>{{len .Crashes}}</td></tr>
+</table>
+{{if .Crashes}}
+<h2>Crashes</h2>
+<table>
+<tr><th>Time</th><th>Frame</th><th>Reason</th></tr>
+{{range .Crashes}}<tr class="Fatal"><td>{{.Time}}</td><td>{{.Frame}}</td><td>{{range .Reason}}<pre>{{.}}</pre>{{end}}</td></tr>
+{{end}}</table>
+{{end}}
+<h2>Top warnings and errors</h2>
+<table>
+<tr><th>Count</th><th>Category</th><th>Verbosity</th><th>Message</th><th>Example</th></tr>
+{{range .TopMessages}}<tr class="{{.Verbosity}}"><td>{{.Count}}</td><td>{{.Category}}</td><td>{{.Verbosity}}</td><td>{{.Message}}</td><td><pre>{{.Example}}</pre></td></tr>
+{{end}}</table>
+<h2>Counts</h2>
+<table>
+<tr><th>Category</th><th>Verbosity</th><th>Count</th></tr>
+{{range .Counts}}<tr class="{{.Verbosity}}"><td>{{.Category}}</td><td>{{.Verbosity}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+// firstLine Get first line of multi-line message for table output
+func firstLine(message string) string {
+	return strings.SplitN(message, "\n", 2)[0]
+}
+
+func writeStatsText(w io.Writer, reports []fileReport) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, report := range reports {
+		fmt.Fprintf(tw, "== %s ==\n", report.Source)
+		fmt.Fprintf(tw, "Logs:\t%d\n", report.TotalLogs)
+		fmt.Fprintf(tw, "First:\t%s [%s]\n", report.FirstTime, report.FirstFrame)
+		fmt.Fprintf(tw, "Last:\t%s [%s]\n", report.LastTime, report.LastFrame)
+		fmt.Fprintf(tw, "Crashes:\t%d\n", len(report.Crashes))
+		for _, crash := range report.Crashes {
+			fmt.Fprintf(tw, "  %s [%s]\n", crash.Time, crash.Frame)
+			for _, reason := range crash.Reason {
+				fmt.Fprintf(tw, "    %s\n", reason)
+			}
+		}
+
+		fmt.Fprintln(tw, "\nTop warnings and errors:")
+		fmt.Fprintln(tw, "Count\tCategory\tVerbosity\tMessage")
+		for _, message := range report.TopMessages {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", message.Count, message.Category, message.Verbosity, firstLine(message.Message))
+		}
+
+		fmt.Fprintln(tw, "\nCounts:")
+		fmt.Fprintln(tw, "Category\tVerbosity\tCount")
+		for _, count := range report.Counts {
+			fmt.Fprintf(tw, "%s\t%s\t%d\n", count.Category, count.Verbosity, count.Count)
+		}
+		fmt.Fprintln(tw, "")
+	}
+	return tw.Flush()
+}
+
+func runStats(args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: uelog stats [flags] <file>...")
+		fmt.Fprintln(flags.Output(), "")
+		fmt.Fprintln(flags.Output(), "Report log counts, top repeated warnings and errors, time range and crashes for each file.")
+		fmt.Fprintln(flags.Output(), "")
+		flags.PrintDefaults()
+	}
+
+	var filterFlags filterFlags
+	filterFlags.register(flags)
+	format := flags.String("format", "text", "Output format: text, json or html")
+	top := flags.Int("top", 20, "Number of top repeated warnings and errors. -1 for all")
+	out := flags.String("o", "", "Output file. Default is stdout")
+	flags.Parse(args)
+
+	// Checked before reading files and creating the output file
+	switch *format {
+	case "text", "json", "html":
+	default:
+		return fmt.Errorf("invalid format: %s", *format)
+	}
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return errors.New("no log file")
+	}
+
+	filter, err := filterFlags.filter()
+	if err != nil {
+		return err
+	}
+
+	reports := []fileReport{}
+	for _, filePath := range flags.Args() {
+		stats := ueloghandler.NewStatsLogHandler()
+		var handler ueloghandler.LogHandler = stats
+		if filter != nil {
+			handler = ueloghandler.NewFilterLogHandler(filter, stats)
+		}
+
+		err := ueloghandler.ReadLogFile(filePath, handler.HandleLog)
+		if err != nil {
+			return err
+		}
+		reports = append(reports, fileReport{Source: filePath, StatsReport: stats.Report(*top)})
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	switch *format {
+	case "text":
+		return writeStatsText(w, reports)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(reports)
+	case "html":
+		return statsHTMLTemplate.Execute(w, reports)
+	default:
+		return fmt.Errorf("invalid format: %s", *format)
+	}
+}
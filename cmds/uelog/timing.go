@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+type fileTimingReport struct {
+	Source string
+	ueloghandler.TimingReport
+}
+
+func writeTimingText(w io.Writer, reports []fileTimingReport) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, report := range reports {
+		fmt.Fprintf(tw, "== %s ==\n", report.Source)
+		fmt.Fprintln(tw, "Kind\tName\tEvents\tTotal(s)\tMax(s)\tMax count")
+		for _, summary := range report.Summaries {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%.3f\t%.3f\t%d\n", summary.Kind, summary.Name, summary.Events, summary.TotalSeconds, summary.MaxSeconds, summary.MaxCount)
+		}
+		fmt.Fprintln(tw, "")
+	}
+	return tw.Flush()
+}
+
+func runTiming(args []string) error {
+	flags := flag.NewFlagSet("timing", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: uelog timing [flags] <file>...")
+		fmt.Fprintln(flags.Output(), "")
+		fmt.Fprintln(flags.Output(), "Report engine initialization, map load, shader compile and asset registry scan timings for each file.")
+		fmt.Fprintln(flags.Output(), "")
+		flags.PrintDefaults()
+	}
+
+	format := flags.String("format", "text", "Output format: text or json")
+	flags.Parse(args)
+
+	// Checked before reading files
+	switch *format {
+	case "text", "json":
+	default:
+		return fmt.Errorf("invalid format: %s", *format)
+	}
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return errors.New("no log file")
+	}
+
+	reports := []fileTimingReport{}
+	for _, filePath := range flags.Args() {
+		timing := ueloghandler.NewTimingLogHandler(ueloghandler.TimingConfig{})
+		err := ueloghandler.ReadLogFile(filePath, timing.HandleLog)
+		if err != nil {
+			return err
+		}
+		reports = append(reports, fileTimingReport{Source: filePath, TimingReport: timing.Report()})
+	}
+
+	switch *format {
+	case "text":
+		return writeTimingText(os.Stdout, reports)
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(reports)
+	default:
+		return fmt.Errorf("invalid format: %s", *format)
+	}
+}
@@ -0,0 +1,197 @@
+package ueloghandler
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultDedupWindow = 10 * time.Second
+
+type DedupConfig struct {
+	// Period in which repeated logs are suppressed. Default is 10 seconds.
+	Window time.Duration
+	// Number of logs with the same key passed in a window before suppression. Default is 1.
+	Burst int
+	// Key to identify repeated logs. Default is DedupByMessage.
+	Key func(log Log) string
+	// Create summary log from the last suppressed log and the number of suppressed logs. Default is DedupSummary.
+	Summary func(last Log, count int) Log
+	// Location of log time. Default is UTC.
+	Location *time.Location
+	// Current time used for logs without time until a log with time is handled. Default is time.Now.
+	// After that, logs without time are regarded as output at the time of the last log with time.
+	Now func() time.Time
+}
+
+// DedupByMessage Identify logs by category, verbosity and message
+func DedupByMessage(log Log) string {
+	return log.Category + "\x00" + log.Verbosity + "\x00" + log.Message()
+}
+
+// DedupByTemplate Identify logs by template ID given by fingerprinter
+func DedupByTemplate(fingerprinter Fingerprinter) func(log Log) string {
+	return func(log Log) string {
+		id, _ := fingerprinter.Fingerprint(log)
+		return id
+	}
+}
+
+// DedupSummary Append "[repeated N times]" to the first line of the last suppressed log
+func DedupSummary(last Log, count int) Log {
+	text := strings.TrimRight(last.Log, "\n")
+	firstLine, rest := text, ""
+	if i := strings.Index(text, "\n"); i >= 0 {
+		firstLine, rest = text[:i], text[i:]
+	}
+
+	summary := last
+	summary.Log = fmt.Sprintf("%s [repeated %d times]%s\n", firstLine, count, rest)
+	return summary
+}
+
+// DedupLogHandler Suppress repeated logs like syslog
+//
+// The first Burst logs with the same key in Window are passed to the handler and the rest are suppressed.
+// When the window ends, a summary log telling the number of suppressed logs is passed to the handler.
+// The window starts at the first log of the key and is measured by log time, so reading saved logs gives the same result as watching.
+// Log time earlier than the start of the newest window is regarded as that start.
+// Windows started by Config.Now before the first log with time end when the first log with time is handled,
+// since wall-clock time and log time can not be compared.
+//
+// Summaries of windows which have not ended are passed when Flush is called.
+type DedupLogHandler struct {
+	config  DedupConfig
+	handler LogHandler
+	mu      sync.Mutex
+	entries map[string]*dedupEntry
+	// Entries in order of window end
+	queue []*dedupEntry
+	// Time of the last log with time. Zero until a log with time is handled.
+	lastTime time.Time
+}
+
+type dedupEntry struct {
+	key        string
+	start      time.Time
+	passed     int
+	suppressed int
+	last       Log
+}
+
+func NewDedupLogHandler(config DedupConfig, handler LogHandler) *DedupLogHandler {
+	if config.Window <= 0 {
+		config.Window = defaultDedupWindow
+	}
+	if config.Burst <= 0 {
+		config.Burst = 1
+	}
+	if config.Key == nil {
+		config.Key = DedupByMessage
+	}
+	if config.Summary == nil {
+		config.Summary = DedupSummary
+	}
+	if config.Location == nil {
+		config.Location = time.UTC
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	return &DedupLogHandler{config: config, handler: handler, entries: make(map[string]*dedupEntry)}
+}
+
+func (h *DedupLogHandler) HandleLog(log Log) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now, err := log.ParseTime(h.config.Location)
+	timed := err == nil
+	if timed {
+		if h.lastTime.IsZero() {
+			// Windows started by wall-clock time end
+			if err := h.summarizeAll(); err != nil {
+				return err
+			}
+		}
+	} else if !h.lastTime.IsZero() {
+		now = h.lastTime
+	} else {
+		now = h.config.Now()
+	}
+	// Time going backward is clamped to the start of the newest window to keep the queue in order of window end
+	if n := len(h.queue); n > 0 && now.Before(h.queue[n-1].start) {
+		now = h.queue[n-1].start
+	}
+	if timed {
+		h.lastTime = now
+	}
+
+	if err := h.expire(now); err != nil {
+		return err
+	}
+
+	key := h.config.Key(log)
+	entry, ok := h.entries[key]
+	if !ok {
+		entry = &dedupEntry{key: key, start: now}
+		h.entries[key] = entry
+		h.queue = append(h.queue, entry)
+	}
+
+	if entry.passed < h.config.Burst {
+		entry.passed++
+		return h.handler.HandleLog(log)
+	}
+
+	entry.suppressed++
+	entry.last = log
+	return nil
+}
+
+// expire Remove entries whose window ended from the front of the queue and pass their summaries
+func (h *DedupLogHandler) expire(now time.Time) error {
+	for len(h.queue) > 0 {
+		entry := h.queue[0]
+		if now.Before(entry.start.Add(h.config.Window)) {
+			break
+		}
+		h.queue[0] = nil
+		h.queue = h.queue[1:]
+		delete(h.entries, entry.key)
+		if err := h.summarize(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *DedupLogHandler) summarize(entry *dedupEntry) error {
+	if entry.suppressed == 0 {
+		return nil
+	}
+	return h.handler.HandleLog(h.config.Summary(entry.last, entry.suppressed))
+}
+
+// Flush Pass summaries of all windows and reset
+func (h *DedupLogHandler) Flush() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.summarizeAll()
+}
+
+// summarizeAll Remove all entries and pass their summaries
+func (h *DedupLogHandler) summarizeAll() error {
+	queue := h.queue
+	h.queue = nil
+	h.entries = make(map[string]*dedupEntry)
+
+	for _, entry := range queue {
+		if err := h.summarize(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package ueloghandler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+func TestDedupLogHandler(t *testing.T) {
+	logs := []string{
+		"[2022.05.01-17.56.38:000][  1]LogTemp: Warning: Actor BP_Enemy_C_1 failed\n",
+		"[2022.05.01-17.56.38:100][  2]LogTemp: Warning: Actor BP_Enemy_C_1 failed\n",
+		"[2022.05.01-17.56.38:200][  3]LogTemp: Warning: Actor BP_Enemy_C_2 failed\n",
+		"[2022.05.01-17.56.39:000][  4]LogTemp: Warning: Actor BP_Enemy_C_1 failed\n",
+		"[2022.05.01-17.56.39:500][  5]LogNet: Player joined\n",
+		"[2022.05.01-17.56.40:000][  6]LogTemp: Warning: Actor BP_Enemy_C_1 failed\n",
+		"[2022.05.01-17.56.40:100][  7]LogNet: Player joined\n",
+	}
+
+	fingerprinter := ueloghandler.NewMaskingFingerprinter()
+
+	type testCase struct {
+		name   string
+		config ueloghandler.DedupConfig
+		// Logs before Flush
+		want []string
+		// Logs passed by Flush
+		wantFlush []string
+	}
+	testCases := []testCase{
+		{
+			name:   "Message",
+			config: ueloghandler.DedupConfig{Window: 2 * time.Second},
+			want: []string{
+				logs[0],
+				logs[2],
+				logs[4],
+				"[2022.05.01-17.56.39:000][  4]LogTemp: Warning: Actor BP_Enemy_C_1 failed [repeated 2 times]\n",
+				logs[5],
+			},
+			wantFlush: []string{
+				"[2022.05.01-17.56.40:100][  7]LogNet: Player joined [repeated 1 times]\n",
+			},
+		},
+		{
+			name:   "Template",
+			config: ueloghandler.DedupConfig{Window: 2 * time.Second, Key: ueloghandler.DedupByTemplate(fingerprinter)},
+			want: []string{
+				logs[0],
+				logs[4],
+				"[2022.05.01-17.56.39:000][  4]LogTemp: Warning: Actor BP_Enemy_C_1 failed [repeated 3 times]\n",
+				logs[5],
+			},
+			wantFlush: []string{
+				"[2022.05.01-17.56.40:100][  7]LogNet: Player joined [repeated 1 times]\n",
+			},
+		},
+		{
+			name:   "Burst",
+			config: ueloghandler.DedupConfig{Window: 10 * time.Second, Burst: 2},
+			want: []string{
+				logs[0],
+				logs[1],
+				logs[2],
+				logs[4],
+				logs[6],
+			},
+			wantFlush: []string{
+				"[2022.05.01-17.56.40:000][  6]LogTemp: Warning: Actor BP_Enemy_C_1 failed [repeated 2 times]\n",
+			},
+		},
+	}
+
+	for i := range testCases {
+		testCase := testCases[i]
+		t.Run(testCase.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var got []string
+			handler := ueloghandler.NewDedupLogHandler(testCase.config, ueloghandler.NewLogHandler(func(log ueloghandler.Log) error {
+				got = append(got, log.Log)
+				return nil
+			}))
+			for _, log := range logs {
+				assert.NoError(handler.HandleLog(ueloghandler.NewLog(log)))
+			}
+			assert.Equal(testCase.want, got)
+
+			got = nil
+			assert.NoError(handler.Flush())
+			assert.Equal(testCase.wantFlush, got)
+		})
+	}
+}
+
+func TestDedupLogHandlerUntimedLogs(t *testing.T) {
+	assert := assert.New(t)
+
+	logs := []string{
+		"Log file open, 05/02/22 13:01:53\n",
+		"Log file open, 05/02/22 13:01:53\n",
+		"[2022.05.01-17.56.38:000][  1]LogTemp: Warning: Failed\n",
+		"LogTemp: Warning: Untimed\n",
+		"LogTemp: Warning: Untimed\n",
+		"[2022.05.01-17.56.39:000][  2]LogTemp: Warning: Failed\n",
+		"LogTemp: Warning: Untimed\n",
+		"[2022.05.01-17.56.40:000][  3]LogNet: Player joined\n",
+	}
+
+	var got []string
+	handler := ueloghandler.NewDedupLogHandler(ueloghandler.DedupConfig{
+		Window: 2 * time.Second,
+		// Wall-clock time far from log time is not mixed with log time
+		Now: func() time.Time { return time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC) },
+	}, ueloghandler.NewLogHandler(func(log ueloghandler.Log) error {
+		got = append(got, log.Log)
+		return nil
+	}))
+	for _, log := range logs {
+		assert.NoError(handler.HandleLog(ueloghandler.NewLog(log)))
+	}
+	assert.NoError(handler.Flush())
+
+	assert.Equal([]string{
+		logs[0],
+		// Window started by wall-clock time ends at the first log with time
+		"Log file open, 05/02/22 13:01:53 [repeated 1 times]\n",
+		logs[2],
+		// Untimed logs are regarded as output at the time of the last log with time
+		logs[3],
+		"[2022.05.01-17.56.39:000][  2]LogTemp: Warning: Failed [repeated 1 times]\n",
+		"LogTemp: Warning: Untimed [repeated 2 times]\n",
+		logs[7],
+	}, got)
+}
+
+func TestDedupSummary(t *testing.T) {
+	assert := assert.New(t)
+
+	log := ueloghandler.NewLog("[2022.05.01-17.56.38:000][  1]LogWindows: Error: Assertion failed\n[Callstack] 0x00007ffe5bc37eef\n")
+	summary := ueloghandler.DedupSummary(log, 5)
+	assert.Equal("[2022.05.01-17.56.38:000][  1]LogWindows: Error: Assertion failed [repeated 5 times]\n[Callstack] 0x00007ffe5bc37eef\n", summary.Log)
+	assert.Equal("LogWindows", summary.Category)
+	assert.Equal("Error", summary.Verbosity)
+}
+
+func TestDedupLogHandlerBackwardTime(t *testing.T) {
+	assert := assert.New(t)
+
+	logs := []string{
+		"[2022.05.01-17.56.40:000][  1]LogTemp: Warning: Failed\n",
+		"[2022.05.01-17.56.40:000][  2]LogTemp: Warning: Failed\n",
+		// Time going backward is regarded as the start of the newest window
+		"[2022.05.01-17.56.30:000][  3]LogNet: Player joined\n",
+		"[2022.05.01-17.56.30:000][  4]LogNet: Player joined\n",
+		"[2022.05.01-17.56.41:000][  5]LogTemp: Warning: Failed\n",
+		"[2022.05.01-17.56.42:000][  6]LogNet: Player joined\n",
+	}
+
+	var got []string
+	handler := ueloghandler.NewDedupLogHandler(ueloghandler.DedupConfig{Window: 2 * time.Second}, ueloghandler.NewLogHandler(func(log ueloghandler.Log) error {
+		got = append(got, log.Log)
+		return nil
+	}))
+	for _, log := range logs {
+		assert.NoError(handler.HandleLog(ueloghandler.NewLog(log)))
+	}
+	assert.NoError(handler.Flush())
+
+	assert.Equal([]string{
+		logs[0],
+		logs[2],
+		"[2022.05.01-17.56.41:000][  5]LogTemp: Warning: Failed [repeated 2 times]\n",
+		"[2022.05.01-17.56.30:000][  4]LogNet: Player joined [repeated 1 times]\n",
+		logs[5],
+	}, got)
+}
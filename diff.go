@@ -0,0 +1,128 @@
+package ueloghandler
+
+import (
+	"io"
+	"sort"
+)
+
+// MessageDiff Change of count of a warning or error message between two logs
+type MessageDiff struct {
+	Category  string
+	Verbosity string
+	// Message normalized by NormalizeMessage
+	Message     string
+	Example     string
+	BaseCount   int
+	TargetCount int
+}
+
+// LogDiff Difference of warnings and errors between two logs
+type LogDiff struct {
+	// Messages only in target
+	New []MessageDiff
+	// Messages only in base
+	Resolved []MessageDiff
+	// Messages in both logs with different counts
+	Changed []MessageDiff
+}
+
+type diffKey struct {
+	category, verbosity, message string
+}
+
+// DiffStats Compare warnings and errors of two reports
+//
+// Messages are compared after normalization, so time, frame, addresses, GUIDs and numbers are ignored.
+// Reports must contain all messages. Use StatsLogHandler.Report(-1).
+// Each list is sorted by category, then by count change in descending order.
+func DiffStats(base, target StatsReport) LogDiff {
+	baseMessages := map[diffKey]RepeatedMessage{}
+	for _, message := range base.TopMessages {
+		baseMessages[diffKey{message.Category, message.Verbosity, message.Message}] = message
+	}
+
+	diff := LogDiff{New: []MessageDiff{}, Resolved: []MessageDiff{}, Changed: []MessageDiff{}}
+	for _, message := range target.TopMessages {
+		key := diffKey{message.Category, message.Verbosity, message.Message}
+		messageDiff := MessageDiff{
+			Category:    message.Category,
+			Verbosity:   message.Verbosity,
+			Message:     message.Message,
+			Example:     message.Example,
+			TargetCount: message.Count,
+		}
+
+		baseMessage, ok := baseMessages[key]
+		if !ok {
+			diff.New = append(diff.New, messageDiff)
+			continue
+		}
+		delete(baseMessages, key)
+
+		if baseMessage.Count != message.Count {
+			messageDiff.BaseCount = baseMessage.Count
+			diff.Changed = append(diff.Changed, messageDiff)
+		}
+	}
+
+	for _, message := range baseMessages {
+		diff.Resolved = append(diff.Resolved, MessageDiff{
+			Category:  message.Category,
+			Verbosity: message.Verbosity,
+			Message:   message.Message,
+			Example:   message.Example,
+			BaseCount: message.Count,
+		})
+	}
+
+	sortMessageDiff(diff.New)
+	sortMessageDiff(diff.Resolved)
+	sortMessageDiff(diff.Changed)
+	return diff
+}
+
+func sortMessageDiff(diffs []MessageDiff) {
+	abs := func(v int) int {
+		if v < 0 {
+			return -v
+		}
+		return v
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		a, b := diffs[i], diffs[j]
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		deltaA, deltaB := abs(a.TargetCount-a.BaseCount), abs(b.TargetCount-b.BaseCount)
+		if deltaA != deltaB {
+			return deltaA > deltaB
+		}
+		if a.Verbosity != b.Verbosity {
+			return VerbosityLevel(a.Verbosity) < VerbosityLevel(b.Verbosity)
+		}
+		return a.Message < b.Message
+	})
+}
+
+// DiffLogs Compare warnings and errors of two logs
+func DiffLogs(base, target io.Reader) (LogDiff, error) {
+	baseReport, err := readStatsReport(base)
+	if err != nil {
+		return LogDiff{}, err
+	}
+	targetReport, err := readStatsReport(target)
+	if err != nil {
+		return LogDiff{}, err
+	}
+	return DiffStats(baseReport, targetReport), nil
+}
+
+func readStatsReport(r io.Reader) (StatsReport, error) {
+	stats := NewStatsLogHandler()
+	reader := NewReader(r)
+	for reader.Next() {
+		stats.HandleLog(reader.Log())
+	}
+	return stats.Report(-1), reader.Err()
+}
@@ -0,0 +1,40 @@
+package ueloghandler_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+func TestDiffLogs(t *testing.T) {
+	assert := assert.New(t)
+
+	base := `[2022.05.01-04.01.58:862][970]LogTemp: Warning: Actor BP_Enemy_C_1 failed at 0x00007ffe5bc37eef
+[2022.05.01-04.01.58:863][971]LogTemp: Warning: Actor BP_Enemy_C_2 failed at 0x00007ffe5bc37ef0
+[2022.05.01-04.01.58:864][972]LogHttp: Error: Request 8D3F2A1B-1C2D-4E5F-8A9B-0C1D2E3F4A5B timeout
+[2022.05.01-04.01.58:865][972]LogNet: Warning: Connection lost
+[2022.05.01-04.01.58:866][973]LogTemp: Display: Not compared
+`
+	target := `[2022.05.02-05.11.08:100][ 10]LogTemp: Warning: Actor BP_Enemy_C_7 failed at 0x00007ffe5bc30000
+[2022.05.02-05.11.08:200][ 11]LogHttp: Error: Request 00000000-1C2D-4E5F-8A9B-0C1D2E3F4A5B timeout
+[2022.05.02-05.11.08:300][ 12]LogAudio: Error: Device lost
+[2022.05.02-05.11.08:400][ 13]LogTemp: Display: Not compared
+[2022.05.02-05.11.08:500][ 14]LogTemp: Display: Not compared
+`
+
+	diff, err := ueloghandler.DiffLogs(strings.NewReader(base), strings.NewReader(target))
+	assert.NoError(err)
+	assert.Equal(ueloghandler.LogDiff{
+		New: []ueloghandler.MessageDiff{
+			{Category: "LogAudio", Verbosity: "Error", Message: "Device lost", Example: "[2022.05.02-05.11.08:300][ 12]LogAudio: Error: Device lost", TargetCount: 1},
+		},
+		Resolved: []ueloghandler.MessageDiff{
+			{Category: "LogNet", Verbosity: "Warning", Message: "Connection lost", Example: "[2022.05.01-04.01.58:865][972]LogNet: Warning: Connection lost", BaseCount: 1},
+		},
+		Changed: []ueloghandler.MessageDiff{
+			{Category: "LogTemp", Verbosity: "Warning", Message: "Actor BP_Enemy_C_<num> failed at <hex>", Example: "[2022.05.02-05.11.08:100][ 10]LogTemp: Warning: Actor BP_Enemy_C_7 failed at 0x00007ffe5bc30000", BaseCount: 2, TargetCount: 1},
+		},
+	}, diff)
+}
@@ -0,0 +1,213 @@
+package ueloghandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type ElasticsearchConfig struct {
+	// URL of bulk API. e.g. http://localhost:9200/_bulk
+	Endpoint string
+	// Name of index to store logs
+	Index string
+	// Additional HTTP headers. e.g. authorization header
+	Headers map[string]string
+	// Value of source field used when Log.Source is empty. e.g. path to log file
+	Source string
+	// Number of logs sent in one request. Default is 1000.
+	BatchSize int
+	// Maximum number of logs kept for retry when sending fails. The oldest logs exceeding it are dropped. Default is 10 times BatchSize.
+	MaxBuffered int
+	// Location of log time. Default is UTC.
+	Location *time.Location
+	// HTTP client used for bulk request. Default is http.DefaultClient.
+	Client *http.Client
+	// Retry settings. Default is DefaultRetryConfig.
+	Retry *RetryConfig
+	// Called with errors of requests in background. If nil, the errors are returned by the next Flush.
+	OnError func(err error)
+}
+
+// ElasticsearchDocument Document stored for each log
+type ElasticsearchDocument struct {
+	Timestamp  string                   `json:"@timestamp,omitempty"`
+	Message    string                   `json:"message"`
+	Category   string                   `json:"category,omitempty"`
+	Verbosity  string                   `json:"verbosity"`
+	Frame      string                   `json:"frame,omitempty"`
+	Source     string                   `json:"source,omitempty"`
+	Structured []map[string]interface{} `json:"structured,omitempty"`
+}
+
+// ElasticsearchLogHandler Store logs to Elasticsearch using bulk API
+//
+// One document is created for each log. Structured log payloads are stored in the structured field as nested objects.
+// Logs are buffered and sent in background when the buffer reaches BatchSize, so HandleLog does not wait for Elasticsearch.
+// Logs of a failed request are kept and sent again on the next request.
+// In the bulk response, documents failed with 429 or 5xx status are sent again and the others failed with 4xx status are dropped.
+// While Elasticsearch is slow or down, the oldest logs exceeding MaxBuffered are dropped.
+// Call Flush to send the remaining logs after watching ends.
+type ElasticsearchLogHandler struct {
+	config    ElasticsearchConfig
+	sender    *httpSender
+	documents *sendBuffer[ElasticsearchDocument]
+	flusher   *backgroundFlusher
+}
+
+func NewElasticsearchLogHandler(config ElasticsearchConfig) *ElasticsearchLogHandler {
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultShipBatchSize
+	}
+	if config.Location == nil {
+		config.Location = time.UTC
+	}
+	h := &ElasticsearchLogHandler{
+		config:    config,
+		sender:    newHTTPSender(config.Client, config.Headers, config.Retry),
+		documents: newSendBuffer[ElasticsearchDocument](config.BatchSize, config.MaxBuffered),
+	}
+	h.flusher = &backgroundFlusher{flush: h.sendBuffered, onError: config.OnError}
+	return h
+}
+
+func (h *ElasticsearchLogHandler) HandleLog(log Log) error {
+	if h.documents.add(h.newDocument(log)) {
+		h.flusher.trigger()
+	}
+	return nil
+}
+
+// Flush Wait for requests in background and send buffered logs
+//
+// Errors of requests in background are returned together if OnError is nil.
+func (h *ElasticsearchLogHandler) Flush(ctx context.Context) error {
+	err := h.flusher.wait(ctx)
+	return joinErrors(err, h.sendBuffered(ctx))
+}
+
+func (h *ElasticsearchLogHandler) sendBuffered(ctx context.Context) error {
+	return h.documents.flush(func(documents []ElasticsearchDocument) ([]ElasticsearchDocument, error) {
+		return h.send(ctx, documents)
+	})
+}
+
+// send Send documents and return documents to be sent again
+func (h *ElasticsearchLogHandler) send(ctx context.Context, documents []ElasticsearchDocument) ([]ElasticsearchDocument, error) {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	action := map[string]interface{}{"index": map[string]string{"_index": h.config.Index}}
+	for _, document := range documents {
+		if err := encoder.Encode(action); err != nil {
+			return nil, err
+		}
+		if err := encoder.Encode(document); err != nil {
+			return nil, err
+		}
+	}
+
+	resBody, err := h.sender.post(ctx, h.config.Endpoint, "application/x-ndjson", body.Bytes())
+	var tempErr *temporaryError
+	if errors.As(err, &tempErr) {
+		return documents, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	retryIndexes, err := checkElasticsearchBulkResponse(resBody)
+	retry := []ElasticsearchDocument{}
+	for _, i := range retryIndexes {
+		if i < len(documents) {
+			retry = append(retry, documents[i])
+		}
+	}
+	return retry, err
+}
+
+func (h *ElasticsearchLogHandler) newDocument(log Log) ElasticsearchDocument {
+	document := ElasticsearchDocument{
+		Message:   strings.TrimRight(log.Log, "\n"),
+		Category:  log.Category,
+		Verbosity: log.Verbosity,
+		Frame:     strings.TrimSpace(log.Frame),
+		Source:    logSource(log, h.config.Source),
+	}
+	if document.Verbosity == "" {
+		document.Verbosity = "Log"
+	}
+
+	if t, err := log.ParseTime(h.config.Location); err == nil {
+		document.Timestamp = t.Format(time.RFC3339Nano)
+	}
+
+	// Payloads which are not valid json are kept only in message
+	for _, jsonStr := range GetStructuredJsonFromLog(log.Log) {
+		var payload map[string]interface{}
+		// Numbers are kept as json.Number so that 64-bit integers are not rounded to float64
+		decoder := json.NewDecoder(strings.NewReader(jsonStr))
+		decoder.UseNumber()
+		if err := decoder.Decode(&payload); err == nil {
+			document.Structured = append(document.Structured, payload)
+		}
+	}
+
+	return document
+}
+
+// checkElasticsearchBulkResponse Check results of items in bulk response
+//
+// Items failed with 429 Too Many Requests or 5xx status may succeed later. Their indexes are returned with *temporaryError.
+// Items failed with other status are rejected by Elasticsearch, e.g. mapping errors, and reported as a permanent error.
+func checkElasticsearchBulkResponse(resBody []byte) ([]int, error) {
+	var res struct {
+		Errors bool
+		Items  []map[string]struct {
+			Status int
+			Error  json.RawMessage
+		}
+	}
+	if err := json.Unmarshal(resBody, &res); err != nil {
+		return nil, err
+	}
+	if !res.Errors {
+		return nil, nil
+	}
+
+	retryIndexes := []int{}
+	rejected := 0
+	var firstRejected, firstRetry string
+	for i, item := range res.Items {
+		for _, result := range item {
+			if len(result.Error) == 0 {
+				continue
+			}
+			if result.Status == http.StatusTooManyRequests || result.Status >= 500 {
+				if len(retryIndexes) == 0 {
+					firstRetry = string(result.Error)
+				}
+				retryIndexes = append(retryIndexes, i)
+			} else {
+				if rejected == 0 {
+					firstRejected = string(result.Error)
+				}
+				rejected++
+			}
+		}
+	}
+
+	var err error
+	if rejected != 0 {
+		err = fmt.Errorf("elasticsearch bulk request failed: %d/%d items: %s", rejected, len(res.Items), firstRejected)
+	}
+	if len(retryIndexes) != 0 {
+		tempErr := &temporaryError{err: fmt.Errorf("elasticsearch bulk request failed temporarily: %d/%d items: %s", len(retryIndexes), len(res.Items), firstRetry)}
+		err = joinErrors(err, tempErr)
+	}
+	return retryIndexes, err
+}
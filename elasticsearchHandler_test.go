@@ -0,0 +1,192 @@
+package ueloghandler_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+func TestElasticsearchLogHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	lines := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("application/x-ndjson", r.Header.Get("Content-Type"))
+
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		w.Write([]byte(`{"took":1,"errors":false,"items":[]}`))
+	}))
+	defer server.Close()
+
+	handler := ueloghandler.NewElasticsearchLogHandler(ueloghandler.ElasticsearchConfig{
+		Endpoint: server.URL,
+		Index:    "ue-logs",
+		Source:   "ue.log",
+	})
+
+	logStr := "[2022.05.01-17.56.38:615][429]LogTemp: Damage" + ueloghandler.BeginStructuredStr + `{"Meta":{"Type":"Damage"},"Body":{"Body":{"Damage":10}}}` + ueloghandler.EndStructuredStr + "\n"
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog(logStr)))
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("Log file open, 05/02/22 02:56:31\n")))
+	assert.NoError(handler.Flush(context.Background()))
+
+	if !assert.Len(lines, 4) {
+		return
+	}
+	assert.JSONEq(`{"index":{"_index":"ue-logs"}}`, lines[0])
+	assert.JSONEq(`{
+		"@timestamp":"2022-05-01T17:56:38.615Z",
+		"message":"[2022.05.01-17.56.38:615][429]LogTemp: Damage_BEGIN_STRUCTURED_{\"Meta\":{\"Type\":\"Damage\"},\"Body\":{\"Body\":{\"Damage\":10}}}_END_STRUCTURED_",
+		"category":"LogTemp",
+		"verbosity":"Log",
+		"frame":"429",
+		"source":"ue.log",
+		"structured":[{"Meta":{"Type":"Damage"},"Body":{"Body":{"Damage":10}}}]
+	}`, lines[1])
+	assert.JSONEq(`{"index":{"_index":"ue-logs"}}`, lines[2])
+	assert.JSONEq(`{"message":"Log file open, 05/02/22 02:56:31","verbosity":"Log","source":"ue.log"}`, lines[3])
+}
+
+func TestElasticsearchLogHandlerItemError(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res := map[string]interface{}{
+			"errors": true,
+			"items": []interface{}{
+				map[string]interface{}{"index": map[string]interface{}{"status": 201}},
+				map[string]interface{}{"index": map[string]interface{}{"status": 400, "error": map[string]interface{}{"type": "mapper_parsing_exception"}}},
+			},
+		}
+		json.NewEncoder(w).Encode(res)
+	}))
+	defer server.Close()
+
+	handler := ueloghandler.NewElasticsearchLogHandler(ueloghandler.ElasticsearchConfig{Endpoint: server.URL, Index: "ue-logs"})
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("LogTemp: test1")))
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("LogTemp: test2")))
+	err := handler.Flush(context.Background())
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "1/2 items")
+		assert.Contains(err.Error(), "mapper_parsing_exception")
+	}
+}
+
+func TestElasticsearchLogHandlerItemRetry(t *testing.T) {
+	assert := assert.New(t)
+
+	bodies := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) > 1 {
+			w.Write([]byte(`{"errors":false,"items":[{"index":{"status":201}}]}`))
+			return
+		}
+		w.Write([]byte(`{"errors":true,"items":[
+			{"index":{"status":201}},
+			{"index":{"status":429,"error":{"type":"es_rejected_execution_exception"}}},
+			{"index":{"status":400,"error":{"type":"mapper_parsing_exception"}}}
+		]}`))
+	}))
+	defer server.Close()
+
+	handler := ueloghandler.NewElasticsearchLogHandler(ueloghandler.ElasticsearchConfig{Endpoint: server.URL, Index: "ue-logs"})
+	for _, log := range []string{"LogTemp: test1", "LogTemp: test2", "LogTemp: test3"} {
+		assert.NoError(handler.HandleLog(ueloghandler.NewLog(log)))
+	}
+	err := handler.Flush(context.Background())
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "mapper_parsing_exception")
+		assert.Contains(err.Error(), "es_rejected_execution_exception")
+	}
+
+	// Only the document rejected with 429 is sent again
+	assert.NoError(handler.Flush(context.Background()))
+	if assert.Len(bodies, 2) {
+		assert.Contains(bodies[1], "test2")
+		assert.NotContains(bodies[1], "test1")
+		assert.NotContains(bodies[1], "test3")
+	}
+}
+
+func TestElasticsearchLogHandlerBackground(t *testing.T) {
+	assert := assert.New(t)
+
+	server := newFailingServer(1, http.StatusServiceUnavailable)
+	server.response = `{"errors":false,"items":[]}`
+	defer server.Close()
+
+	var errs errorRecorder
+	retry := ueloghandler.RetryConfig{MaxRetries: 0}
+	handler := ueloghandler.NewElasticsearchLogHandler(ueloghandler.ElasticsearchConfig{Endpoint: server.URL, Index: "ue-logs", BatchSize: 1, Retry: &retry, OnError: errs.onError})
+	// Failed request in background does not stop the watcher
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("LogTemp: test1")))
+	assert.NoError(handler.Flush(context.Background()))
+	assert.Contains(errs.messages(), "503 Service Unavailable")
+
+	bodies := server.succeededBodies()
+	if assert.Len(bodies, 1) {
+		assert.Contains(bodies[0], "test1")
+	}
+}
+
+func TestElasticsearchLogHandlerRequeue(t *testing.T) {
+	assert := assert.New(t)
+
+	server := newFailingServer(1, http.StatusTooManyRequests)
+	server.response = `{"errors":false,"items":[]}`
+	defer server.Close()
+
+	retry := ueloghandler.RetryConfig{MaxRetries: 0}
+	handler := ueloghandler.NewElasticsearchLogHandler(ueloghandler.ElasticsearchConfig{Endpoint: server.URL, Index: "ue-logs", Retry: &retry})
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("LogTemp: test1")))
+	assert.Error(handler.Flush(context.Background()))
+
+	// Documents of the failed request are sent again
+	assert.NoError(handler.Flush(context.Background()))
+	bodies := server.succeededBodies()
+	if assert.Len(bodies, 1) {
+		assert.Contains(bodies[0], "test1")
+	}
+}
+
+func TestElasticsearchLogHandlerInt64(t *testing.T) {
+	assert := assert.New(t)
+
+	lines := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		w.Write([]byte(`{"took":1,"errors":false,"items":[]}`))
+	}))
+	defer server.Close()
+
+	handler := ueloghandler.NewElasticsearchLogHandler(ueloghandler.ElasticsearchConfig{Endpoint: server.URL, Index: "ue-logs"})
+
+	logStr := "[2022.05.01-17.56.38:615][429]LogTemp: " + ueloghandler.BeginStructuredStr + `{"Meta":{"Type":"Id"},"Body":{"Body":{"Max":18446744073709551615,"Min":-9223372036854775808}}}` + ueloghandler.EndStructuredStr + "\n"
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog(logStr)))
+	assert.NoError(handler.Flush(context.Background()))
+
+	if !assert.Len(lines, 2) {
+		return
+	}
+	var document struct {
+		Structured []json.RawMessage
+	}
+	assert.NoError(json.Unmarshal([]byte(lines[1]), &document))
+	if assert.Len(document.Structured, 1) {
+		assert.Equal(`{"Body":{"Body":{"Max":18446744073709551615,"Min":-9223372036854775808}},"Meta":{"Type":"Id"}}`, string(document.Structured[0]))
+	}
+}
@@ -7,19 +7,18 @@ import (
 	"io"
 	"io/fs"
 	"os"
-	"strings"
 	"time"
 )
 
 var ErrFileRemoved = errors.New("ueLogHandler:File removed")
 
 type FileNotifier struct {
-	logs               chan string
-	readBytes          int64
-	sb                 strings.Builder
-	basicFormatSection bool
-	watchInterval      time.Duration
-	filePath           string
+	logs          chan string
+	readBytes     int64
+	grouper       logGrouper
+	watchInterval time.Duration
+	filePath      string
+	readExisting  bool
 }
 
 func NewFileNotifier(filePath string, watchInterval time.Duration) *FileNotifier {
@@ -27,6 +26,18 @@ func NewFileNotifier(filePath string, watchInterval time.Duration) *FileNotifier
 	return wacher
 }
 
+// SetReadExisting Send logs already written when Subscribe is called if readExisting is true
+//
+// By default, logs are read when the file is updated after Subscribe is called.
+func (f *FileNotifier) SetReadExisting(readExisting bool) {
+	f.readExisting = readExisting
+}
+
+// Source Get path to the watched log file
+func (f *FileNotifier) Source() string {
+	return f.filePath
+}
+
 func (f *FileNotifier) Flush() error {
 	err := f.read(f.filePath)
 	f.sendUnsentLog()
@@ -38,14 +49,12 @@ func (f *FileNotifier) Flush() error {
 // The log is sent when the next log is started.
 // Therefore, there may be an unsent log when the Watch method ends.
 func (f *FileNotifier) sendUnsentLog() {
-	if f.sb.Len() > 0 {
-		f.logs <- f.sb.String()
-		f.sb.Reset()
-	}
-
+	f.grouper.flush(f.send)
 	f.readBytes = 0
-	f.sb.Reset()
-	f.basicFormatSection = false
+}
+
+func (f *FileNotifier) send(logStr string) {
+	f.logs <- logStr
 }
 
 func (f *FileNotifier) Logs() chan string {
@@ -62,6 +71,13 @@ func (f *FileNotifier) Subscribe(ctx context.Context) error {
 	}
 	latestModTime := fstat.ModTime()
 
+	if f.readExisting {
+		err = f.read(f.filePath)
+		if err != nil {
+			return err
+		}
+	}
+
 	ticker := time.NewTicker(f.watchInterval)
 	for {
 		select {
@@ -69,6 +85,8 @@ func (f *FileNotifier) Subscribe(ctx context.Context) error {
 			fstat, err := os.Stat(f.filePath)
 			if err != nil {
 				if errors.Is(err, fs.ErrNotExist) {
+					// Flush can not read removed file, so the unsent log is sent here
+					f.sendUnsentLog()
 					return ErrFileRemoved
 				}
 				return err
@@ -93,24 +111,42 @@ func (f *FileNotifier) read(filePath string) error {
 	}
 	defer file.Close()
 
-	fs, err := file.Stat()
-	if err != nil {
+	header := make([]byte, len(zstdMagic))
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
 		return err
 	}
-	if fileRecreated := fs.Size() < f.readBytes; fileRecreated {
-		f.sendUnsentLog()
-	}
 
-	_, err = file.Seek(f.readBytes, io.SeekStart)
-	if err != nil {
-		return err
+	var src io.Reader
+	if detectCompression(header[:n]) == compressionNone {
+		fs, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		if fileRecreated := fs.Size() < f.readBytes; fileRecreated {
+			f.sendUnsentLog()
+		}
+
+		_, err = file.Seek(f.readBytes, io.SeekStart)
+		if err != nil {
+			return err
+		}
+		src = file
+	} else {
+		decompressReader, err := f.openDecompressed(file)
+		if err != nil {
+			return err
+		}
+		defer decompressReader.Close()
+		src = decompressReader
 	}
 
-	bufreader := bufio.NewReader(file)
+	bufreader := bufio.NewReader(src)
 	for {
 		lineData, err := bufreader.ReadBytes('\n')
 		if err != nil {
-			if err == io.EOF {
+			// Compressed file being written ends with incomplete data
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
 				return nil
 			}
 			return err
@@ -120,28 +156,37 @@ func (f *FileNotifier) read(filePath string) error {
 		lineStr := string(lineData)
 		lineStr = ToUTF8_LF(lineStr)
 
-		logInfo := NewLog(lineStr)
+		f.grouper.push(lineStr, f.send)
+	}
+}
 
-		startBasicFormatLog := logInfo.Category != "" || logInfo.Time != ""
+// openDecompressed Open decompressed stream of gzip or zstd file and skip read bytes
+//
+// Compressed file can not be seeked, so it is decompressed from the beginning on every update.
+// Reading cost grows with the file size, so watching a large compressed file being written is slow.
+// readBytes is counted in decompressed bytes.
+func (f *FileNotifier) openDecompressed(file *os.File) (io.ReadCloser, error) {
+	for {
+		_, err := file.Seek(0, io.SeekStart)
+		if err != nil {
+			return nil, err
+		}
 
-		if f.basicFormatSection {
-			// The log may output multiple lines.
-			// Therefore, when the start of the next log output is detected, the string up to that point is as a log.
-			if startBasicFormatLog {
-				f.logs <- f.sb.String()
-				f.sb.Reset()
-				f.sb.WriteString(lineStr)
-			} else {
-				f.sb.WriteString(lineStr)
-			}
-		} else {
-			if startBasicFormatLog {
-				f.sb.Reset()
-				f.sb.WriteString(lineStr)
-				f.basicFormatSection = true
-			} else {
-				f.logs <- lineStr
-			}
+		decompressReader, err := NewDecompressReader(file)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = io.CopyN(io.Discard, decompressReader, f.readBytes)
+		if err == nil {
+			return decompressReader, nil
+		}
+		decompressReader.Close()
+
+		if fileRecreated := err == io.EOF && f.readBytes > 0; fileRecreated {
+			f.sendUnsentLog()
+			continue
 		}
+		return nil, err
 	}
 }
@@ -226,3 +226,74 @@ line3
 		t.Run(testCase.Name, testCase.Run)
 	}
 }
+
+func subscribeFileNotifier(notifier *ueloghandler.FileNotifier, update func()) ([]string, error) {
+	receiveLogs := []string{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for log := range notifier.Logs() {
+			receiveLogs = append(receiveLogs, log)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	subscribeEnd := make(chan error)
+	go func() {
+		subscribeEnd <- notifier.Subscribe(ctx)
+	}()
+
+	time.Sleep(time.Millisecond * 100)
+	update()
+	time.Sleep(time.Millisecond * 100)
+
+	cancel()
+	err := <-subscribeEnd
+	close(notifier.Logs())
+	<-done
+	return receiveLogs, err
+}
+
+func TestFileNotifierReadExisting(t *testing.T) {
+	for _, readExisting := range []bool{false, true} {
+		tmpFile, err := NewTestLogFile()
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer tmpFile.Close()
+		assert.NoError(t, os.WriteFile(tmpFile.Name(), []byte("LogTemp: Existing1\nLogTemp: Existing2\n"), 0666))
+
+		notifier := ueloghandler.NewFileNotifier(tmpFile.Name(), time.Millisecond)
+		notifier.SetReadExisting(readExisting)
+		// The file is not updated while subscribing
+		logs, err := subscribeFileNotifier(notifier, func() {})
+		assert.NoError(t, err)
+		if readExisting {
+			// The last log is sent by Flush
+			assert.Equal(t, []string{"LogTemp: Existing1\n"}, logs)
+		} else {
+			assert.Equal(t, []string{}, logs)
+		}
+	}
+}
+
+func TestFileNotifierRemoved(t *testing.T) {
+	assert := assert.New(t)
+
+	tmpFile, err := NewTestLogFile()
+	if !assert.NoError(err) {
+		return
+	}
+	defer tmpFile.Close()
+	assert.NoError(os.WriteFile(tmpFile.Name(), []byte("LogTemp: Log1\nLogTemp: Log2\nline2\n"), 0666))
+
+	notifier := ueloghandler.NewFileNotifier(tmpFile.Name(), time.Millisecond)
+	notifier.SetReadExisting(true)
+	logs, err := subscribeFileNotifier(notifier, func() {
+		assert.NoError(os.Remove(tmpFile.Name()))
+	})
+	assert.ErrorIs(err, ueloghandler.ErrFileRemoved)
+	// The last log is sent although the removed file can not be flushed
+	assert.Equal([]string{"LogTemp: Log1\n", "LogTemp: Log2\nline2\n"}, logs)
+}
@@ -0,0 +1,150 @@
+package ueloghandler
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+var ErrInvalidVerbosity = errors.New("ueLogHandler:Invalid verbosity")
+
+// Verbosity levels. The values are the same as ELogVerbosity.
+const (
+	VerbosityLevelFatal       = 1
+	VerbosityLevelError       = 2
+	VerbosityLevelWarning     = 3
+	VerbosityLevelDisplay     = 4
+	VerbosityLevelLog         = 5
+	VerbosityLevelVerbose     = 6
+	VerbosityLevelVeryVerbose = 7
+)
+
+// VerbosityLevel Convert verbosity to level
+//
+// Logs without verbosity are output with ELogVerbosity::Log.
+func VerbosityLevel(verbosity string) int {
+	switch verbosity {
+	case "Fatal":
+		return VerbosityLevelFatal
+	case "Error":
+		return VerbosityLevelError
+	case "Warning":
+		return VerbosityLevelWarning
+	case "Display":
+		return VerbosityLevelDisplay
+	case "Verbose":
+		return VerbosityLevelVerbose
+	case "VeryVerbose":
+		return VerbosityLevelVeryVerbose
+	default:
+		return VerbosityLevelLog
+	}
+}
+
+// ParseVerbosityLevel Convert verbosity name to level
+//
+// Unlike VerbosityLevel, an unknown verbosity name is an error.
+func ParseVerbosityLevel(verbosity string) (int, error) {
+	if verbosity == "Log" {
+		return VerbosityLevelLog, nil
+	}
+	level := VerbosityLevel(verbosity)
+	if level == VerbosityLevelLog {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidVerbosity, verbosity)
+	}
+	return level, nil
+}
+
+// LogFilter Return true if the log should be handled
+type LogFilter func(log Log) bool
+
+// NewFilterLogHandler Create log handler passing only logs matching filter to handler
+func NewFilterLogHandler(filter LogFilter, handler LogHandler) LogHandler {
+	return NewLogHandler(func(log Log) error {
+		if !filter(log) {
+			return nil
+		}
+		return handler.HandleLog(log)
+	})
+}
+
+// AllFilter Match logs matching all filters
+func AllFilter(filters ...LogFilter) LogFilter {
+	return func(log Log) bool {
+		for _, filter := range filters {
+			if !filter(log) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// CategoryFilter Match logs of any of categories
+func CategoryFilter(categories ...string) LogFilter {
+	categorySet := map[string]struct{}{}
+	for _, category := range categories {
+		categorySet[category] = struct{}{}
+	}
+	return func(log Log) bool {
+		_, ok := categorySet[log.Category]
+		return ok
+	}
+}
+
+// VerbosityFilter Match logs whose verbosity is equal to or more severe than verbosity
+//
+// ErrInvalidVerbosity is returned if verbosity is not a verbosity name.
+//
+// Example:
+//
+//	VerbosityFilter("Warning") matches Fatal, Error and Warning logs.
+func VerbosityFilter(verbosity string) (LogFilter, error) {
+	level, err := ParseVerbosityLevel(verbosity)
+	if err != nil {
+		return nil, err
+	}
+	return func(log Log) bool {
+		return VerbosityLevel(log.Verbosity) <= level
+	}, nil
+}
+
+// PatternFilter Match logs matching pattern
+func PatternFilter(pattern *regexp.Regexp) LogFilter {
+	return func(log Log) bool {
+		return pattern.MatchString(log.Log)
+	}
+}
+
+// TimeRangeFilter Match logs output in [since, until)
+//
+// Zero since or until means unbounded. Logs without time do not match.
+func TimeRangeFilter(since, until time.Time, loc *time.Location) LogFilter {
+	return func(log Log) bool {
+		t, err := log.ParseTime(loc)
+		if err != nil {
+			return false
+		}
+		if !since.IsZero() && t.Before(since) {
+			return false
+		}
+		if !until.IsZero() && !t.Before(until) {
+			return false
+		}
+		return true
+	}
+}
+
+// TemplateFilter Match logs of any of template IDs given by fingerprinter
+func TemplateFilter(fingerprinter Fingerprinter, ids ...string) LogFilter {
+	idSet := map[string]struct{}{}
+	for _, id := range ids {
+		idSet[id] = struct{}{}
+	}
+	return func(log Log) bool {
+		id, _ := fingerprinter.Fingerprint(log)
+		_, ok := idSet[id]
+		return ok
+	}
+}
@@ -0,0 +1,91 @@
+package ueloghandler_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+func verbosityFilter(t *testing.T, verbosity string) ueloghandler.LogFilter {
+	filter, err := ueloghandler.VerbosityFilter(verbosity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filter
+}
+
+func TestLogFilter(t *testing.T) {
+	logs := []ueloghandler.Log{
+		ueloghandler.NewLog("[2022.05.01-17.56.38:600][  9]LogHttp: Warning: Cleaning up 0 outstanding Http requests."),
+		ueloghandler.NewLog("[2022.05.01-17.56.39:600][ 10]LogTemp: Error: Error log"),
+		ueloghandler.NewLog("[2022.05.01-17.56.40:600][ 11]LogTemp: Log"),
+		ueloghandler.NewLog("[2022.05.01-17.56.41:600][ 12]LogTemp: Verbose: Verbose log"),
+		ueloghandler.NewLog("Log file open, 05/02/22 13:01:53"),
+	}
+
+	type testCase struct {
+		name   string
+		filter ueloghandler.LogFilter
+		want   []int
+	}
+	testCases := []testCase{
+		{name: "Category", filter: ueloghandler.CategoryFilter("LogTemp"), want: []int{1, 2, 3}},
+		{name: "CategoryMulti", filter: ueloghandler.CategoryFilter("LogTemp", "LogHttp"), want: []int{0, 1, 2, 3}},
+		{name: "VerbosityWarning", filter: verbosityFilter(t, "Warning"), want: []int{0, 1}},
+		{name: "VerbosityLog", filter: verbosityFilter(t, "Log"), want: []int{0, 1, 2, 4}},
+		{name: "Pattern", filter: ueloghandler.PatternFilter(regexp.MustCompile(`(?i)error|open`)), want: []int{1, 4}},
+		{
+			name: "TimeRange",
+			filter: ueloghandler.TimeRangeFilter(
+				time.Date(2022, 5, 1, 17, 56, 39, 0, time.UTC),
+				time.Date(2022, 5, 1, 17, 56, 41, int(600*time.Millisecond), time.UTC),
+				time.UTC,
+			),
+			want: []int{1, 2},
+		},
+		{name: "TimeRangeUnbounded", filter: ueloghandler.TimeRangeFilter(time.Time{}, time.Time{}, time.UTC), want: []int{0, 1, 2, 3}},
+		{
+			name:   "All",
+			filter: ueloghandler.AllFilter(ueloghandler.CategoryFilter("LogTemp"), verbosityFilter(t, "Log")),
+			want:   []int{1, 2},
+		},
+	}
+
+	for i := range testCases {
+		testCase := testCases[i]
+		t.Run(testCase.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			handled := []ueloghandler.Log{}
+			handler := ueloghandler.NewFilterLogHandler(testCase.filter, ueloghandler.NewLogHandler(func(log ueloghandler.Log) error {
+				handled = append(handled, log)
+				return nil
+			}))
+			for _, log := range logs {
+				assert.NoError(handler.HandleLog(log))
+			}
+
+			want := []ueloghandler.Log{}
+			for _, idx := range testCase.want {
+				want = append(want, logs[idx])
+			}
+			assert.Equal(want, handled)
+		})
+	}
+}
+
+func TestVerbosityFilterInvalid(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, verbosity := range []string{"Warnings", "warning", ""} {
+		_, err := ueloghandler.VerbosityFilter(verbosity)
+		assert.ErrorIs(err, ueloghandler.ErrInvalidVerbosity, verbosity)
+	}
+
+	level, err := ueloghandler.ParseVerbosityLevel("Fatal")
+	assert.NoError(err)
+	assert.Equal(ueloghandler.VerbosityLevelFatal, level)
+}
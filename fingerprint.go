@@ -0,0 +1,175 @@
+package ueloghandler
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+)
+
+// Fingerprinter Get message template of log and its ID
+//
+// Logs with the same message except for variable parts such as numbers have the same template ID.
+// Fingerprinting the same log again returns the same template ID, so handlers sharing a Fingerprinter get the same ID for a log.
+type Fingerprinter interface {
+	Fingerprint(log Log) (id string, template string)
+}
+
+// DefaultFingerprintMaskRules ObjectNameMaskRule followed by DefaultMaskRules
+var DefaultFingerprintMaskRules = append([]MaskRule{ObjectNameMaskRule}, DefaultMaskRules...)
+
+func templateID(log Log, template string) string {
+	h := fnv.New64a()
+	h.Write([]byte(log.Category))
+	h.Write([]byte{0})
+	h.Write([]byte(log.Verbosity))
+	h.Write([]byte{0})
+	h.Write([]byte(template))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// FingerprintHandlerFunc Handle log with its message template
+type FingerprintHandlerFunc func(log Log, templateID, template string) error
+
+// NewFingerprintLogHandler Create log handler passing log with its message template to handle
+//
+// Templates are not stored in Log. Handlers such as TemplateFilter, DedupByTemplate and MetricsLogHandler look them up with the same Fingerprinter.
+func NewFingerprintLogHandler(fingerprinter Fingerprinter, handle FingerprintHandlerFunc) LogHandler {
+	return NewLogHandler(func(log Log) error {
+		templateID, template := fingerprinter.Fingerprint(log)
+		return handle(log, templateID, template)
+	})
+}
+
+// MaskingFingerprinter Fingerprinter using message masked by rules as template
+//
+// The template ID is the hash of category, verbosity and template, so it is stable across runs.
+type MaskingFingerprinter struct {
+	rules []MaskRule
+}
+
+// NewMaskingFingerprinter Create MaskingFingerprinter. DefaultFingerprintMaskRules is used if rules is empty.
+func NewMaskingFingerprinter(rules ...MaskRule) *MaskingFingerprinter {
+	if len(rules) == 0 {
+		rules = DefaultFingerprintMaskRules
+	}
+	return &MaskingFingerprinter{rules: rules}
+}
+
+func (f *MaskingFingerprinter) Fingerprint(log Log) (string, string) {
+	template := Mask(log.Message(), f.rules)
+	return templateID(log, template), template
+}
+
+const drainWildcard = "<*>"
+
+const defaultDrainSimilarity = 0.5
+
+// DrainFingerprinter Fingerprinter mining templates from logs with Drain algorithm
+//
+// Messages are masked by rules and split into tokens.
+// A message is merged into the most similar template of the same category, verbosity, token count and first token,
+// and tokens which differ from the template are replaced with "<*>".
+// A new template is created if no template is similar enough.
+//
+// The template ID is given when the template is created and does not change when tokens are replaced.
+// Since templates depend on the order of logs, IDs are stable only within the same DrainFingerprinter.
+//
+// He, Pinjia, et al. "Drain: An online log parsing approach with fixed depth tree." ICWS 2017.
+type DrainFingerprinter struct {
+	rules      []MaskRule
+	similarity float64
+	mu         sync.Mutex
+	groups     map[string][]*drainCluster
+}
+
+type drainCluster struct {
+	id     string
+	tokens []string
+}
+
+// NewDrainFingerprinter Create DrainFingerprinter
+//
+// similarity is the ratio of equal tokens required to merge a message into a template. Default is 0.5.
+// DefaultFingerprintMaskRules is used if rules is empty.
+func NewDrainFingerprinter(similarity float64, rules ...MaskRule) *DrainFingerprinter {
+	if similarity <= 0 {
+		similarity = defaultDrainSimilarity
+	}
+	if len(rules) == 0 {
+		rules = DefaultFingerprintMaskRules
+	}
+	return &DrainFingerprinter{rules: rules, similarity: similarity, groups: make(map[string][]*drainCluster)}
+}
+
+func (f *DrainFingerprinter) Fingerprint(log Log) (string, string) {
+	tokens := strings.Fields(Mask(log.Message(), f.rules))
+
+	firstToken := ""
+	if len(tokens) > 0 {
+		firstToken = tokens[0]
+	}
+	groupKey := fmt.Sprintf("%s\x00%s\x00%d\x00%s", log.Category, log.Verbosity, len(tokens), firstToken)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var best *drainCluster
+	bestSimilarity := -1.0
+	for _, cluster := range f.groups[groupKey] {
+		similarity := cluster.similarity(tokens)
+		if similarity > bestSimilarity {
+			best = cluster
+			bestSimilarity = similarity
+		}
+	}
+
+	if best == nil || bestSimilarity < f.similarity {
+		best = &drainCluster{tokens: tokens}
+		best.id = templateID(log, best.template())
+		f.groups[groupKey] = append(f.groups[groupKey], best)
+	} else {
+		best.merge(tokens)
+	}
+
+	return best.id, best.template()
+}
+
+// Templates Get all templates mined so far by ID
+func (f *DrainFingerprinter) Templates() map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	templates := map[string]string{}
+	for _, clusters := range f.groups {
+		for _, cluster := range clusters {
+			templates[cluster.id] = cluster.template()
+		}
+	}
+	return templates
+}
+
+func (c *drainCluster) similarity(tokens []string) float64 {
+	if len(tokens) == 0 {
+		return 1
+	}
+	equal := 0
+	for i, token := range tokens {
+		if c.tokens[i] == token {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(tokens))
+}
+
+func (c *drainCluster) merge(tokens []string) {
+	for i, token := range tokens {
+		if c.tokens[i] != token {
+			c.tokens[i] = drainWildcard
+		}
+	}
+}
+
+func (c *drainCluster) template() string {
+	return strings.Join(c.tokens, " ")
+}
@@ -0,0 +1,100 @@
+package ueloghandler_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+func TestMaskingFingerprinter(t *testing.T) {
+	assert := assert.New(t)
+
+	fingerprinter := ueloghandler.NewMaskingFingerprinter()
+
+	id1, template1 := fingerprinter.Fingerprint(ueloghandler.NewLog("[2022.05.02-04.01.58:862][970]LogTemp: Warning: Actor BP_Enemy_C_1 failed to load /Game/Maps/Map01"))
+	id2, template2 := fingerprinter.Fingerprint(ueloghandler.NewLog("[2022.05.02-04.01.58:863][971]LogTemp: Warning: Actor BP_Enemy_C_25 failed to load /Game/Maps/Map02"))
+	id3, _ := fingerprinter.Fingerprint(ueloghandler.NewLog("[2022.05.02-04.01.58:863][971]LogNet: Warning: Actor BP_Enemy_C_25 failed to load /Game/Maps/Map02"))
+	id4, _ := fingerprinter.Fingerprint(ueloghandler.NewLog("[2022.05.02-04.01.58:863][971]LogTemp: Error: Actor BP_Enemy_C_25 failed to load /Game/Maps/Map02"))
+
+	assert.Equal("Actor <object> failed to load <path>", template1)
+	assert.Equal(template1, template2)
+	assert.Equal(id1, id2)
+	assert.NotEqual(id1, id3, "category is part of ID")
+	assert.NotEqual(id1, id4, "verbosity is part of ID")
+
+	// ID is stable across fingerprinters
+	id5, _ := ueloghandler.NewMaskingFingerprinter().Fingerprint(ueloghandler.NewLog("[2022.05.02-04.01.59:000][980]LogTemp: Warning: Actor BP_Enemy_C_3 failed to load /Game/Maps/Map03"))
+	assert.Equal(id1, id5)
+
+	_, custom := ueloghandler.NewMaskingFingerprinter(ueloghandler.DefaultMaskRules...).Fingerprint(ueloghandler.NewLog("[2022.05.02-04.01.58:862][970]LogTemp: Warning: Actor BP_Enemy_C_1 failed"))
+	assert.Equal("Actor BP_Enemy_C_<num> failed", custom)
+}
+
+func TestDrainFingerprinter(t *testing.T) {
+	assert := assert.New(t)
+
+	fingerprinter := ueloghandler.NewDrainFingerprinter(0)
+
+	id1, template1 := fingerprinter.Fingerprint(ueloghandler.NewLog("[2022.05.02-04.01.58:862][970]LogNet: Player Alice joined the session"))
+	assert.Equal("Player Alice joined the session", template1)
+
+	id2, template2 := fingerprinter.Fingerprint(ueloghandler.NewLog("[2022.05.02-04.01.58:863][971]LogNet: Player Bob joined the session"))
+	assert.Equal("Player <*> joined the session", template2)
+	assert.Equal(id1, id2, "ID does not change when template is merged")
+
+	id3, template3 := fingerprinter.Fingerprint(ueloghandler.NewLog("[2022.05.02-04.01.58:864][972]LogNet: Player Carol left the session"))
+	assert.Equal("Player <*> <*> the session", template3)
+	assert.Equal(id1, id3)
+
+	id4, template4 := fingerprinter.Fingerprint(ueloghandler.NewLog("[2022.05.02-04.01.58:865][973]LogNet: Player kicked by server for idle"))
+	assert.Equal("Player kicked by server for idle", template4)
+	assert.NotEqual(id1, id4, "not similar enough")
+
+	id5, _ := fingerprinter.Fingerprint(ueloghandler.NewLog("[2022.05.02-04.01.58:866][974]LogNet: Player Dave joined"))
+	assert.NotEqual(id1, id5, "token count differs")
+
+	id6, _ := fingerprinter.Fingerprint(ueloghandler.NewLog("[2022.05.02-04.01.58:867][975]LogTemp: Player Erin joined the session"))
+	assert.NotEqual(id1, id6, "category differs")
+
+	assert.Equal(map[string]string{
+		id1: "Player <*> <*> the session",
+		id4: "Player kicked by server for idle",
+		id5: "Player Dave joined",
+		id6: "Player Erin joined the session",
+	}, fingerprinter.Templates())
+}
+
+func TestFingerprintLogHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	type fingerprinted struct {
+		log                  ueloghandler.Log
+		templateID, template string
+	}
+	var got []fingerprinted
+	fingerprinter := ueloghandler.NewDrainFingerprinter(0)
+	handler := ueloghandler.NewFingerprintLogHandler(fingerprinter, func(log ueloghandler.Log, templateID, template string) error {
+		got = append(got, fingerprinted{log: log, templateID: templateID, template: template})
+		return nil
+	})
+
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("[2022.05.02-04.01.58:865][972]LogHttp: Error: Request timeout 30s")))
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("[2022.05.02-04.01.58:866][973]LogHttp: Error: Request timeout 45s")))
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("[2022.05.02-04.01.58:867][974]LogHttp: Error: Connection refused")))
+
+	if !assert.Len(got, 3) {
+		return
+	}
+	assert.Equal("Request timeout <num>s", got[0].template)
+	assert.NotEmpty(got[0].templateID)
+	assert.Equal(got[0].templateID, got[1].templateID)
+	assert.NotEqual(got[0].templateID, got[2].templateID)
+
+	// Templates are looked up with the same fingerprinter without mining again
+	filter := ueloghandler.TemplateFilter(fingerprinter, got[0].templateID)
+	assert.True(filter(got[0].log))
+	assert.True(filter(got[1].log))
+	assert.False(filter(got[2].log))
+	assert.Len(fingerprinter.Templates(), 2)
+}
@@ -0,0 +1,164 @@
+package gen
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// CompatibilityError Change of structure which breaks handling payloads of released game builds
+type CompatibilityError struct {
+	Structure string
+	// Field of current body. Empty if the error is not related to a field.
+	Field  string
+	Reason string
+}
+
+func (e CompatibilityError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("%s: %s", e.Structure, e.Reason)
+	}
+	return fmt.Sprintf("%s.%s: %s", e.Structure, e.Field, e.Reason)
+}
+
+type CompatibilityErrors []CompatibilityError
+
+func (e CompatibilityErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return "incompatible structure changes: " + strings.Join(messages, "; ")
+}
+
+// CheckCompatibility Check that handlers generated from current can handle payloads of builds generated from released
+//
+// The following changes are reported as CompatibilityErrors.
+//
+//	Structure removed
+//	Version decreased
+//	Body changed without increasing Version
+//	Field added without default in Changes
+//	Field type changed
+func CheckCompatibility(released, current StructureInfoList) error {
+	var errs CompatibilityErrors
+
+	for _, structureName := range sortedKeys(released) {
+		releasedInfo := released[structureName]
+		currentInfo, ok := current[structureName]
+		if !ok {
+			errs = append(errs, CompatibilityError{Structure: structureName, Reason: "structure removed"})
+			continue
+		}
+
+		releasedVersion, currentVersion := releasedInfo.CurrentVersion(), currentInfo.CurrentVersion()
+		if currentVersion < releasedVersion {
+			errs = append(errs, CompatibilityError{Structure: structureName, Reason: fmt.Sprintf("version decreased from %d to %d", releasedVersion, currentVersion)})
+			continue
+		}
+		if currentVersion == releasedVersion {
+			if !reflect.DeepEqual(releasedInfo.Body, currentInfo.Body) {
+				errs = append(errs, CompatibilityError{Structure: structureName, Reason: fmt.Sprintf("body changed without increasing version %d", currentVersion)})
+			}
+			continue
+		}
+
+		for _, fieldName := range sortedKeys(currentInfo.Body) {
+			typeName := currentInfo.Body[fieldName]
+			releasedName, added := releasedFieldName(currentInfo, releasedVersion, fieldName)
+			if added {
+				continue
+			}
+
+			releasedType, ok := releasedInfo.Body[releasedName]
+			switch {
+			case !ok:
+				errs = append(errs, CompatibilityError{Structure: structureName, Field: fieldName, Reason: fmt.Sprintf("field added without default for version %d", releasedVersion)})
+			case releasedType != typeName:
+				errs = append(errs, CompatibilityError{Structure: structureName, Field: fieldName, Reason: fmt.Sprintf("type changed from %s to %s", releasedType, typeName)})
+			}
+		}
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+// CheckSchemaCompatibility Check compatibility of structures, structs and enums
+//
+// In addition to CheckCompatibility, changes of structs existing in both schemas are reported
+// since payloads of released builds do not match the changed struct.
+// Values removed or renamed from enums existing in both schemas are also reported
+// since released builds still output them. Added values are compatible.
+func CheckSchemaCompatibility(released, current Schema) error {
+	var errs CompatibilityErrors
+	if err := CheckCompatibility(released.List, current.List); err != nil {
+		errs = append(errs, err.(CompatibilityErrors)...)
+	}
+
+	for _, structName := range sortedKeys(released.Structs) {
+		currentFields, ok := current.Structs[structName]
+		if ok && !reflect.DeepEqual(released.Structs[structName], currentFields) {
+			errs = append(errs, CompatibilityError{Structure: structName, Reason: "struct changed"})
+		}
+	}
+
+	for _, enumName := range sortedKeys(released.Enums) {
+		currentValues, ok := current.Enums[enumName]
+		if !ok {
+			continue
+		}
+		for _, value := range released.Enums[enumName] {
+			if !containsString(currentValues, value) {
+				errs = append(errs, CompatibilityError{Structure: enumName, Reason: fmt.Sprintf("enum value %s removed", value)})
+			}
+		}
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+// releasedFieldName Trace field of current version back to releasedVersion
+//
+// Returns true if the field is added with default after releasedVersion.
+func releasedFieldName(info StructureInfo, releasedVersion int, fieldName string) (string, bool) {
+	name := fieldName
+	for i := len(info.Changes) - 1; i >= 0; i-- {
+		change := info.Changes[i]
+		if change.Version <= releasedVersion {
+			break
+		}
+		// Defaults are applied after Renames in a change
+		if _, ok := change.Defaults[name]; ok {
+			return name, true
+		}
+		if oldName, ok := change.Renames[name]; ok {
+			name = oldName
+		}
+	}
+	return name, false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
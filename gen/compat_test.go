@@ -0,0 +1,201 @@
+package gen_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/y-akahori-ramen/ueLogHandler/gen"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	released := `
+structures:
+  list:
+    Damage:
+      Meta:
+        Tag: Combat
+      Body:
+        Damage: int32
+        Position: vector3`
+
+	type testCase struct {
+		yaml       string
+		wantErrors gen.CompatibilityErrors
+	}
+
+	testCases := []testCase{
+		{
+			// Not changed
+			yaml: released,
+		},
+		{
+			// Renamed, added with default and removed
+			yaml: `
+structures:
+  list:
+    Damage:
+      Version: 2
+      Body:
+        HitDamage: int32
+        Weapon: string
+      Changes:
+        - Version: 2
+          Renames:
+            HitDamage: Damage
+          Defaults:
+            Weapon: Unknown`,
+		},
+		{
+			// Changed over versions
+			yaml: `
+structures:
+  list:
+    Damage:
+      Version: 3
+      Body:
+        Amount: int32
+        Position: vector3
+        Weapon: string
+      Changes:
+        - Version: 2
+          Renames:
+            HitDamage: Damage
+        - Version: 3
+          Renames:
+            Amount: HitDamage
+          Defaults:
+            Weapon: Unknown`,
+		},
+		{
+			yaml: `
+structures:
+  list:
+    Other:
+      Body:
+        Damage: int32`,
+			wantErrors: gen.CompatibilityErrors{{Structure: "Damage", Reason: "structure removed"}},
+		},
+		{
+			yaml: `
+structures:
+  list:
+    Damage:
+      Body:
+        Damage: int32`,
+			wantErrors: gen.CompatibilityErrors{{Structure: "Damage", Reason: "body changed without increasing version 1"}},
+		},
+		{
+			yaml: `
+structures:
+  list:
+    Damage:
+      Version: 2
+      Body:
+        Damage: int64
+        Position: vector3
+        Weapon: string`,
+			wantErrors: gen.CompatibilityErrors{
+				{Structure: "Damage", Field: "Damage", Reason: "type changed from int32 to int64"},
+				{Structure: "Damage", Field: "Weapon", Reason: "field added without default for version 1"},
+			},
+		},
+	}
+
+	releasedData, err := gen.ReadStructureInfoYAML(strings.NewReader(released))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for i := range testCases {
+		testCase := testCases[i]
+		t.Run(fmt.Sprintf("Case%d", i), func(t *testing.T) {
+			assert := assert.New(t)
+
+			currentData, err := gen.ReadStructureInfoYAML(strings.NewReader(testCase.yaml))
+			if !assert.NoError(err) {
+				return
+			}
+
+			err = gen.CheckCompatibility(releasedData, currentData)
+			if testCase.wantErrors == nil {
+				assert.NoError(err)
+			} else {
+				assert.Equal(testCase.wantErrors, err)
+			}
+		})
+	}
+
+	newer, err := gen.ReadStructureInfoYAML(strings.NewReader(`
+structures:
+  list:
+    Damage:
+      Version: 2
+      Body:
+        Damage: int32
+        Position: vector3`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, gen.CompatibilityErrors{{Structure: "Damage", Reason: "version decreased from 2 to 1"}}, gen.CheckCompatibility(newer, releasedData))
+	}
+}
+
+func TestCheckSchemaCompatibility(t *testing.T) {
+	assert := assert.New(t)
+
+	released, err := gen.ReadSchemaYAML(strings.NewReader(`
+structures:
+  enums:
+    Weapon: [Sword, Bow, Axe]
+  structs:
+    Hit:
+      Bone: name
+  list:
+    Damage:
+      Body:
+        Weapon: Weapon
+        Hit: Hit`))
+	if !assert.NoError(err) {
+		return
+	}
+
+	current, err := gen.ReadSchemaYAML(strings.NewReader(`
+structures:
+  enums:
+    Weapon: [Sword, Longbow, Spear]
+  structs:
+    Hit:
+      Bone: name
+      Normal: vector3
+  list:
+    Damage:
+      Body:
+        Weapon: Weapon
+        Hit: Hit`))
+	if !assert.NoError(err) {
+		return
+	}
+
+	assert.Equal(gen.CompatibilityErrors{
+		{Structure: "Hit", Reason: "struct changed"},
+		{Structure: "Weapon", Reason: "enum value Bow removed"},
+		{Structure: "Weapon", Reason: "enum value Axe removed"},
+	}, gen.CheckSchemaCompatibility(released, current))
+
+	// Added enum values are compatible
+	added, err := gen.ReadSchemaYAML(strings.NewReader(`
+structures:
+  enums:
+    Weapon: [Sword, Bow, Axe, Spear]
+  structs:
+    Hit:
+      Bone: name
+  list:
+    Damage:
+      Body:
+        Weapon: Weapon
+        Hit: Hit`))
+	if assert.NoError(err) {
+		assert.NoError(gen.CheckSchemaCompatibility(released, added))
+	}
+}
@@ -0,0 +1,404 @@
+package gen
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// BlueprintConfig Options of generated Blueprint function library
+type BlueprintConfig struct {
+	// File name of generated library header. e.g. StructuredLogLibrary.h
+	// The class is named after the file as UnrealHeaderTool requires. e.g. UStructuredLogLibrary
+	HeaderName string
+	// API macro of module exporting the class. e.g. MYGAME_API. Optional.
+	APIMacro string
+	// Config of header generated by GenCppSchemaFile. The library calls its logging functions.
+	Cpp CppConfig
+}
+
+func (c BlueprintConfig) className() string {
+	return "U" + strings.TrimSuffix(c.HeaderName, filepath.Ext(c.HeaderName))
+}
+
+func (c BlueprintConfig) validate() error {
+	if c.HeaderName == "" {
+		return errors.New("BlueprintConfig: HeaderName is required")
+	}
+	if c.Cpp.HeaderName == "" {
+		return errors.New("BlueprintConfig: Cpp.HeaderName is required")
+	}
+	return nil
+}
+
+// blueprintStructName Get name of USTRUCT mirroring struct of schema
+//
+// Structs generated by GenCppSchemaFile are not USTRUCT and can not be used in Blueprint.
+func blueprintStructName(structName string) string {
+	return "FStructuredLog" + structName
+}
+
+// isBlueprintUnsigned Blueprint has no unsigned integers. uint32 and uint64 are passed as int64.
+func isBlueprintUnsigned(t fieldType) bool {
+	return t.Kind == fieldKindBuiltin && (t.Name == "uint32" || t.Name == "uint64")
+}
+
+// toBlueprintTypeName Get C++ type of field type in Blueprint
+//
+// Optional is passed as its element type with bHas<Name> flag since Blueprint has no TOptional.
+func toBlueprintTypeName(t fieldType) string {
+	switch {
+	case t.Kind == fieldKindStruct:
+		return blueprintStructName(t.Name)
+	case t.Kind == fieldKindArray:
+		return fmt.Sprintf("TArray<%s>", toBlueprintTypeName(*t.Elem))
+	case t.Kind == fieldKindOptional:
+		return toBlueprintTypeName(*t.Elem)
+	case isBlueprintUnsigned(t):
+		return "int64"
+	default:
+		return toCppValueTypeName(t)
+	}
+}
+
+// isBlueprintScalar Whether the type is passed by value
+func isBlueprintScalar(t fieldType) bool {
+	if t.Kind == fieldKindOptional {
+		return isBlueprintScalar(*t.Elem)
+	}
+	if t.Kind == fieldKindEnum {
+		return true
+	}
+	if t.Kind != fieldKindBuiltin {
+		return false
+	}
+	switch t.Name {
+	case "float", "double", "int32", "uint32", "int64", "uint64", "bool":
+		return true
+	default:
+		return false
+	}
+}
+
+// toBlueprintInitializer Get initializer of USTRUCT member. Empty if the type has a constructor.
+func toBlueprintInitializer(schema Schema, t fieldType) string {
+	if t.Kind == fieldKindOptional {
+		t = *t.Elem
+	}
+	if !isBlueprintScalar(t) {
+		return ""
+	}
+	switch {
+	case t.Kind == fieldKindEnum:
+		return fmt.Sprintf(" = E%s::%s", t.Name, schema.Enums[t.Name][0])
+	case t.Name == "bool":
+		return " = false"
+	default:
+		return " = 0"
+	}
+}
+
+// toCppNativeTypeName Get C++ type of field type out of namespace of header generated by GenCppSchemaFile
+func toCppNativeTypeName(namespace string, t fieldType) string {
+	switch t.Kind {
+	case fieldKindStruct:
+		return fmt.Sprintf("%s::F%s", namespace, t.Name)
+	case fieldKindArray:
+		return fmt.Sprintf("TArray<%s>", toCppNativeTypeName(namespace, *t.Elem))
+	case fieldKindOptional:
+		return fmt.Sprintf("TOptional<%s>", toCppNativeTypeName(namespace, *t.Elem))
+	default:
+		return toCppValueTypeName(t)
+	}
+}
+
+// toCppNativeValue Convert Blueprint value to argument of logging function
+//
+// hasValue is the bHas<Name> flag of optional.
+func toCppNativeValue(namespace string, t fieldType, value, hasValue string) string {
+	switch {
+	case t.Kind == fieldKindStruct:
+		return fmt.Sprintf("ToStructuredLogNative(%s)", value)
+	case t.Kind == fieldKindArray && isBlueprintUnsigned(*t.Elem):
+		return fmt.Sprintf("ToStructuredLogNativeArray<%s>(%s)", t.Elem.Name, value)
+	case t.Kind == fieldKindOptional:
+		nativeType := toCppNativeTypeName(namespace, t)
+		return fmt.Sprintf("%s ? %s(%s) : %s()", hasValue, nativeType, toCppNativeValue(namespace, *t.Elem, value, ""), nativeType)
+	case isBlueprintUnsigned(t):
+		return fmt.Sprintf("ToStructuredLogUnsigned<%s>(%s)", t.Name, value)
+	default:
+		return value
+	}
+}
+
+// blueprintParam Parameter of Blueprint function or member of USTRUCT
+type blueprintParam struct {
+	TypeName, Name, Initializer string
+}
+
+// newBlueprintParams Get Blueprint parameters of field. Optional has bHas<Name> flag before the value.
+func newBlueprintParams(schema Schema, typeName, fieldName string) ([]blueprintParam, error) {
+	t, err := schema.parseType(typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	params := []blueprintParam{}
+	if t.Kind == fieldKindOptional {
+		params = append(params, blueprintParam{TypeName: "bool", Name: "bHas" + fieldName, Initializer: " = false"})
+	}
+	return append(params, blueprintParam{
+		TypeName:    toBlueprintTypeName(t),
+		Name:        fieldName,
+		Initializer: toBlueprintInitializer(schema, t),
+	}), nil
+}
+
+// toBlueprintArgTypeName Get type of Blueprint function parameter
+func toBlueprintArgTypeName(schema Schema, typeName string, param blueprintParam) (string, error) {
+	t, err := schema.parseType(typeName)
+	if err != nil {
+		return "", err
+	}
+	if param.TypeName == "bool" || isBlueprintScalar(t) {
+		return param.TypeName, nil
+	}
+	return fmt.Sprintf("const %s&", param.TypeName), nil
+}
+
+// genBlueprintStructCode Generate USTRUCT mirroring struct of schema
+func genBlueprintStructCode(w io.Writer, schema Schema, structName string, fields StructInfo) error {
+	_, err := fmt.Fprintf(w, "USTRUCT(BlueprintType)\nstruct %s\n{\n\tGENERATED_BODY()\n", blueprintStructName(structName))
+	if err != nil {
+		return err
+	}
+
+	for _, fieldName := range sortedKeys(fields) {
+		params, err := newBlueprintParams(schema, fields[fieldName], fieldName)
+		if err != nil {
+			return err
+		}
+		for _, param := range params {
+			_, err = fmt.Fprintf(w, "\n\tUPROPERTY(EditAnywhere, BlueprintReadWrite)\n\t%s %s%s;\n", param.TypeName, param.Name, param.Initializer)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = fmt.Fprint(w, "};\n\n")
+	return err
+}
+
+// blueprintFuncParams Get parameters of Blueprint function of structure
+func blueprintFuncParams(schema Schema, info StructureInfo) (string, error) {
+	params := []string{}
+	for _, fieldName := range sortedKeys(info.Body) {
+		typeName := info.Body[fieldName]
+		fieldParams, err := newBlueprintParams(schema, typeName, fieldName)
+		if err != nil {
+			return "", err
+		}
+		for _, param := range fieldParams {
+			argTypeName, err := toBlueprintArgTypeName(schema, typeName, param)
+			if err != nil {
+				return "", err
+			}
+			params = append(params, fmt.Sprintf("%s %s", argTypeName, param.Name))
+		}
+	}
+	return strings.Join(params, ", "), nil
+}
+
+// GenBlueprintHeaderFile Generate header of UBlueprintFunctionLibrary with Log<Name> function of each structure
+//
+// Structs of schema are mirrored as USTRUCT named FStructuredLog<Name>.
+func GenBlueprintHeaderFile(w io.Writer, config BlueprintConfig, schema Schema) error {
+	err := config.validate()
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, `// Code generated by structuregen. DO NOT EDIT.
+#pragma once
+#include "CoreMinimal.h"
+#include "Kismet/BlueprintFunctionLibrary.h"
+#include "%s"
+#include "%s.generated.h"
+
+`, config.Cpp.HeaderName, strings.TrimSuffix(config.HeaderName, filepath.Ext(config.HeaderName)))
+	if err != nil {
+		return err
+	}
+
+	structNames, err := schema.sortedStructNames()
+	if err != nil {
+		return err
+	}
+	for _, structName := range structNames {
+		err := genBlueprintStructCode(w, schema, structName, schema.Structs[structName])
+		if err != nil {
+			return err
+		}
+	}
+
+	apiMacro := ""
+	if config.APIMacro != "" {
+		apiMacro = config.APIMacro + " "
+	}
+	_, err = fmt.Fprintf(w, "UCLASS()\nclass %s%s : public UBlueprintFunctionLibrary\n{\n\tGENERATED_BODY()\n\npublic:\n", apiMacro, config.className())
+	if err != nil {
+		return err
+	}
+
+	for i, structureName := range sortedKeys(schema.List) {
+		params, err := blueprintFuncParams(schema, schema.List[structureName])
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			_, err = fmt.Fprintln(w)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = fmt.Fprintf(w, "\tUFUNCTION(BlueprintCallable, Category = \"Structured Log\")\n\tstatic void Log%s(%s);\n", structureName, params)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintln(w, "};")
+	return err
+}
+
+// cppNativeUnsignedCode Convert int64 passed from Blueprint to unsigned integers
+//
+// Values out of range of the unsigned type are clamped instead of wrapping around.
+const cppNativeUnsignedCode = `template <typename TNative>
+TNative ToStructuredLogUnsigned(int64 Value)
+{
+	if (Value < 0)
+	{
+		return 0;
+	}
+	if (static_cast<uint64>(Value) > static_cast<uint64>(TNumericLimits<TNative>::Max()))
+	{
+		return TNumericLimits<TNative>::Max();
+	}
+	return static_cast<TNative>(Value);
+}
+
+template <typename TNative>
+TArray<TNative> ToStructuredLogNativeArray(const TArray<int64>& Values)
+{
+	TArray<TNative> Result;
+	Result.Reserve(Values.Num());
+	for (int64 Value : Values)
+	{
+		Result.Add(ToStructuredLogUnsigned<TNative>(Value));
+	}
+	return Result;
+}
+
+`
+
+// genBlueprintNativeStructCode Generate conversion from USTRUCT mirror to struct of header generated by GenCppSchemaFile
+func genBlueprintNativeStructCode(w io.Writer, namespace string, schema Schema, structName string, fields StructInfo) error {
+	nativeName := toCppNativeTypeName(namespace, fieldType{Kind: fieldKindStruct, Name: structName})
+	_, err := fmt.Fprintf(w, "inline %s ToStructuredLogNative(const %s& Value)\n{\n\t%s Native;\n", nativeName, blueprintStructName(structName), nativeName)
+	if err != nil {
+		return err
+	}
+
+	for _, fieldName := range sortedKeys(fields) {
+		t, err := schema.parseType(fields[fieldName])
+		if err != nil {
+			return err
+		}
+		value := toCppNativeValue(namespace, t, "Value."+fieldName, "Value.bHas"+fieldName)
+		_, err = fmt.Fprintf(w, "\tNative.%s = %s;\n", fieldName, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprint(w, "\treturn Native;\n}\n\n")
+	return err
+}
+
+// GenBlueprintSourceFile Generate source of library generated by GenBlueprintHeaderFile
+//
+// Functions output logs with Category and Verbosity of structures. Structures without Category use LogBlueprintUserMessages like Print String.
+// Like UE_LOG_<Name> macros, logs are compiled out if STRUCTURED_LOG_ENABLED is 0.
+// Negative or too large values of unsigned integer parameters are clamped to the range of the type.
+func GenBlueprintSourceFile(w io.Writer, config BlueprintConfig, schema Schema) error {
+	err := config.validate()
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "// Code generated by structuregen. DO NOT EDIT.\n#include \"%s\"\n#include \"EngineLogs.h\"\n\n%s\n", config.HeaderName, cppLogEnabledCode)
+	if err != nil {
+		return err
+	}
+
+	namespace := config.Cpp.Namespace
+	_, err = io.WriteString(w, cppNativeUnsignedCode)
+	if err != nil {
+		return err
+	}
+
+	structNames, err := schema.sortedStructNames()
+	if err != nil {
+		return err
+	}
+	for _, structName := range structNames {
+		err := genBlueprintNativeStructCode(w, namespace, schema, structName, schema.Structs[structName])
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, structureName := range sortedKeys(schema.List) {
+		info := schema.List[structureName]
+		if verbosityLevel(info.LogVerbosity()) < 0 {
+			return fmt.Errorf("GenBlueprintSourceFile: Name: %s Invalid verbosity %s", structureName, info.LogVerbosity())
+		}
+		params, err := blueprintFuncParams(schema, info)
+		if err != nil {
+			return err
+		}
+
+		args := []string{}
+		for _, fieldName := range sortedKeys(info.Body) {
+			t, err := schema.parseType(info.Body[fieldName])
+			if err != nil {
+				return err
+			}
+			args = append(args, toCppNativeValue(namespace, t, fieldName, "bHas"+fieldName))
+		}
+
+		category := info.Category
+		if category == "" {
+			category = "LogBlueprintUserMessages"
+		}
+
+		// Logging function is qualified since it has the same name as the member function
+		_, err = fmt.Fprintf(w, `void %s::Log%s(%s)
+{
+#if STRUCTURED_LOG_ENABLED
+	UE_LOG(%s, %s, TEXT("%%s"), *%s::Log%s(%s));
+#endif
+}
+
+`, config.className(), structureName, params, category, info.LogVerbosity(), namespace, structureName, strings.Join(args, ", "))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package gen_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/y-akahori-ramen/ueLogHandler/gen"
+)
+
+func TestGenBlueprintFile(t *testing.T) {
+	schema, err := gen.ReadSchemaYAML(strings.NewReader(`
+structures:
+  enums:
+    Weapon: [Sword, Bow]
+  structs:
+    Position:
+      Id: uint32
+      Location: vector3
+    Target:
+      At: Position
+      Weapon: optional<Weapon>
+  list:
+    Damage:
+      Category: LogCombat
+      Verbosity: Display
+      Body:
+        Amount: int32
+        Ids: array<uint64>
+        Name: string
+        Target: optional<Target>
+    Heal:
+      Body:
+        Count: uint32
+        Where: Position`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	testCases := []struct {
+		name           string
+		config         gen.BlueprintConfig
+		expectedHeader []string
+		expectedSource []string
+	}{
+		{
+			name:   "Namespace",
+			config: gen.BlueprintConfig{HeaderName: "StructuredLogLibrary.h", APIMacro: "MYGAME_API", Cpp: gen.CppConfig{Namespace: "structuredLog", HeaderName: "sample.h"}},
+			expectedHeader: []string{
+				"#include \"sample.h\"\n#include \"StructuredLogLibrary.generated.h\"\n",
+				// USTRUCT mirrors are declared in order of dependency
+				"struct FStructuredLogPosition\n{\n\tGENERATED_BODY()\n\n\tUPROPERTY(EditAnywhere, BlueprintReadWrite)\n\tint64 Id = 0;\n\n\tUPROPERTY(EditAnywhere, BlueprintReadWrite)\n\tFVector Location;\n};\n\nUSTRUCT(BlueprintType)\nstruct FStructuredLogTarget\n",
+				"\tbool bHasWeapon = false;\n\n\tUPROPERTY(EditAnywhere, BlueprintReadWrite)\n\tEWeapon Weapon = EWeapon::Sword;\n",
+				"class MYGAME_API UStructuredLogLibrary : public UBlueprintFunctionLibrary\n",
+				"\tUFUNCTION(BlueprintCallable, Category = \"Structured Log\")\n\tstatic void LogDamage(int32 Amount, const TArray<int64>& Ids, const FString& Name, bool bHasTarget, const FStructuredLogTarget& Target);\n",
+				"\tstatic void LogHeal(int64 Count, const FStructuredLogPosition& Where);\n",
+			},
+			expectedSource: []string{
+				// EngineLogs.h declares LogBlueprintUserMessages
+				"#include \"StructuredLogLibrary.h\"\n#include \"EngineLogs.h\"\n",
+				// Unsigned integers are clamped instead of wrapping around
+				"\tif (Value < 0)\n\t{\n\t\treturn 0;\n\t}\n\tif (static_cast<uint64>(Value) > static_cast<uint64>(TNumericLimits<TNative>::Max()))\n\t{\n\t\treturn TNumericLimits<TNative>::Max();\n\t}\n",
+				"\tNative.Id = ToStructuredLogUnsigned<uint32>(Value.Id);\n",
+				"\tNative.Weapon = Value.bHasWeapon ? TOptional<EWeapon>(Value.Weapon) : TOptional<EWeapon>();\n",
+				"void UStructuredLogLibrary::LogDamage(int32 Amount, const TArray<int64>& Ids, const FString& Name, bool bHasTarget, const FStructuredLogTarget& Target)\n{\n#if STRUCTURED_LOG_ENABLED\n\tUE_LOG(LogCombat, Display, TEXT(\"%s\"), *structuredLog::LogDamage(Amount, ToStructuredLogNativeArray<uint64>(Ids), Name, bHasTarget ? TOptional<structuredLog::FTarget>(ToStructuredLogNative(Target)) : TOptional<structuredLog::FTarget>()));\n#endif\n}\n",
+				// Structure without Category
+				"\tUE_LOG(LogBlueprintUserMessages, Log, TEXT(\"%s\"), *structuredLog::LogHeal(ToStructuredLogUnsigned<uint32>(Count), ToStructuredLogNative(Where)));\n",
+			},
+		},
+		{
+			name:   "Global namespace",
+			config: gen.BlueprintConfig{HeaderName: "Library.h", Cpp: gen.CppConfig{HeaderName: "sample.h"}},
+			expectedHeader: []string{
+				"class ULibrary : public UBlueprintFunctionLibrary\n",
+			},
+			expectedSource: []string{
+				"inline ::FPosition ToStructuredLogNative(const FStructuredLogPosition& Value)\n",
+				// Qualified not to call the member function itself
+				"*::LogHeal(",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var header bytes.Buffer
+			if !assert.NoError(gen.GenBlueprintHeaderFile(&header, testCase.config, schema)) {
+				return
+			}
+			for _, expected := range testCase.expectedHeader {
+				assert.Contains(header.String(), expected)
+			}
+
+			var source bytes.Buffer
+			if !assert.NoError(gen.GenBlueprintSourceFile(&source, testCase.config, schema)) {
+				return
+			}
+			for _, expected := range testCase.expectedSource {
+				assert.Contains(source.String(), expected)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	assert.Error(t, gen.GenBlueprintHeaderFile(&buf, gen.BlueprintConfig{Cpp: gen.CppConfig{HeaderName: "sample.h"}}, schema))
+	assert.Error(t, gen.GenBlueprintSourceFile(&buf, gen.BlueprintConfig{HeaderName: "Library.h"}, schema))
+
+	// Schemas built in Go are not validated by schema.cue
+	config := gen.BlueprintConfig{HeaderName: "Library.h", Cpp: gen.CppConfig{HeaderName: "sample.h"}}
+	for _, verbosity := range []string{"Fatal", "Invalid"} {
+		invalidSchema := gen.Schema{List: gen.StructureInfoList{"Damage": {Verbosity: verbosity, Body: gen.StructInfo{"Amount": "int32"}}}}
+		assert.Error(t, gen.GenBlueprintSourceFile(&buf, config, invalidSchema), verbosity)
+	}
+}
@@ -2,8 +2,10 @@ package gen
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -18,6 +20,8 @@ func toCppTypeName(typename string) (string, error) {
 		return "const FVector2D&", nil
 	case "vector3":
 		return "const FVector&", nil
+	case "rotator", "quat", "linearcolor", "name", "text", "guid", "datetime":
+		return fmt.Sprintf("const %s&", cppValueTypeNames[typename]), nil
 	case "float":
 		fallthrough
 	case "double":
@@ -39,24 +43,32 @@ func toCppTypeName(typename string) (string, error) {
 
 func toCppTypeFormat(typename string) (string, error) {
 	switch typename {
-	case "string":
-		return `"%s"`, nil
+	case "string", "name", "text":
+		return "%s", nil // Quoted by StructuredLogJsonString
 	case "vector2":
-		return `{"X":%f,"Y":%f}`, nil
+		return `{"X":%s,"Y":%s}`, nil
 	case "vector3":
-		return `{"X":%f,"Y":%f,"Z":%f}`, nil
+		return `{"X":%s,"Y":%s,"Z":%s}`, nil
+	case "rotator":
+		return `{"Pitch":%s,"Yaw":%s,"Roll":%s}`, nil
+	case "quat":
+		return `{"X":%s,"Y":%s,"Z":%s,"W":%s}`, nil
+	case "linearcolor":
+		return `{"R":%s,"G":%s,"B":%s,"A":%s}`, nil
+	case "guid", "datetime":
+		return `"%s"`, nil // No character needs escaping
 	case "float":
-		return "%f", nil
+		fallthrough
 	case "double":
-		return "%lf", nil
+		return "%s", nil // Written by StructuredLogJsonFloat or StructuredLogJsonDouble
 	case "int32":
 		return "%d", nil
 	case "uint32":
 		return "%u", nil
 	case "int64":
-		return "%ld", nil
+		return "%lld", nil // long long is 64-bit on both LP64 and LLP64
 	case "uint64":
-		return "%lu", nil
+		return "%llu", nil
 	case "bool":
 		return `%s`, nil // true or false
 	default:
@@ -64,45 +76,194 @@ func toCppTypeFormat(typename string) (string, error) {
 	}
 }
 
+// toCppComponentParams Get format params of floating point components of struct such as FVector
+func toCppComponentParams(argname string, components ...string) string {
+	params := make([]string, 0, len(components))
+	for _, component := range components {
+		params = append(params, fmt.Sprintf("*StructuredLogJsonDouble(%s.%s)", argname, component))
+	}
+	return strings.Join(params, ",")
+}
+
 func toCppFormatParam(typename, argname string) (string, error) {
 	switch typename {
 	case "string":
-		return fmt.Sprintf("*%s", argname), nil
+		return fmt.Sprintf("*StructuredLogJsonString(%s)", argname), nil
 	case "vector2":
-		return fmt.Sprintf("%s.X,%s.Y", argname, argname), nil
+		return toCppComponentParams(argname, "X", "Y"), nil
 	case "vector3":
-		return fmt.Sprintf("%s.X,%s.Y,%s.Z", argname, argname, argname), nil
+		return toCppComponentParams(argname, "X", "Y", "Z"), nil
+	case "rotator":
+		return toCppComponentParams(argname, "Pitch", "Yaw", "Roll"), nil
+	case "quat":
+		return toCppComponentParams(argname, "X", "Y", "Z", "W"), nil
+	case "linearcolor":
+		return toCppComponentParams(argname, "R", "G", "B", "A"), nil
+	case "name", "text":
+		return fmt.Sprintf("*StructuredLogJsonString(%s.ToString())", argname), nil
+	case "guid":
+		return fmt.Sprintf("*%s.ToString(EGuidFormats::DigitsWithHyphens)", argname), nil
+	case "datetime":
+		return fmt.Sprintf("*%s.ToIso8601()", argname), nil
 	case "bool":
 		return fmt.Sprintf(`%s?TEXT("true"):TEXT("false")`, argname), nil // true or false
 	case "float":
-		fallthrough
+		return fmt.Sprintf("*StructuredLogJsonFloat(%s)", argname), nil
 	case "double":
-		fallthrough
+		return fmt.Sprintf("*StructuredLogJsonDouble(%s)", argname), nil
+	case "int64":
+		// int64 is long on some platforms. Cast to match %lld.
+		return fmt.Sprintf("static_cast<long long>(%s)", argname), nil
+	case "uint64":
+		return fmt.Sprintf("static_cast<unsigned long long>(%s)", argname), nil
 	case "int32":
 		fallthrough
 	case "uint32":
-		fallthrough
-	case "int64":
-		fallthrough
-	case "uint64":
 		return argname, nil
 	default:
 		return "", fmt.Errorf("toCppFormatParam:Invalid typename:%s", typename)
 	}
 }
 
+// cppJSONValueHelperCode Functions writing JSON values used by all generated logging functions
+//
+// They are defined out of namespace and guarded by STRUCTUREDLOG_JSON_HELPERS so that multiple generated headers can be included together.
+// Strings are escaped as JSON strings. NaN and infinity are written as null since JSON can not represent them.
+// Floating point numbers are written with enough digits to restore the same value.
+const cppJSONValueHelperCode = `#ifndef STRUCTUREDLOG_JSON_HELPERS
+#define STRUCTUREDLOG_JSON_HELPERS
+inline FString StructuredLogJsonString(const FString& Value)
+{
+	FString Json;
+	Json.Reserve(Value.Len() + 2);
+	Json.AppendChar(TEXT('"'));
+	for (const TCHAR Char : Value)
+	{
+		switch (Char)
+		{
+		case TEXT('"'):
+			Json += TEXT("\\\"");
+			break;
+		case TEXT('\\'):
+			Json += TEXT("\\\\");
+			break;
+		case TEXT('\n'):
+			Json += TEXT("\\n");
+			break;
+		case TEXT('\r'):
+			Json += TEXT("\\r");
+			break;
+		case TEXT('\t'):
+			Json += TEXT("\\t");
+			break;
+		default:
+			if (Char < 0x20)
+			{
+				Json += FString::Printf(TEXT("\\u%04x"), static_cast<uint32>(Char));
+			}
+			else
+			{
+				Json.AppendChar(Char);
+			}
+			break;
+		}
+	}
+	Json.AppendChar(TEXT('"'));
+	return Json;
+}
+
+inline FString StructuredLogJsonFloat(float Value)
+{
+	if (!FMath::IsFinite(Value))
+	{
+		return TEXT("null");
+	}
+	return FString::Printf(TEXT("%.9g"), Value);
+}
+
+inline FString StructuredLogJsonDouble(double Value)
+{
+	if (!FMath::IsFinite(Value))
+	{
+		return TEXT("null");
+	}
+	return FString::Printf(TEXT("%.17g"), Value);
+}
+#endif
+
+`
+
+// cppValueTypeNames C++ types of builtin types
+var cppValueTypeNames = map[string]string{
+	"float":       "float",
+	"double":      "double",
+	"int32":       "int32",
+	"uint32":      "uint32",
+	"int64":       "int64",
+	"uint64":      "uint64",
+	"bool":        "bool",
+	"string":      "FString",
+	"vector2":     "FVector2D",
+	"vector3":     "FVector",
+	"rotator":     "FRotator",
+	"quat":        "FQuat",
+	"linearcolor": "FLinearColor",
+	"name":        "FName",
+	"text":        "FText",
+	"guid":        "FGuid",
+	"datetime":    "FDateTime",
+}
+
+// toCppValueTypeName Get C++ type of field type used for struct members and template arguments
+func toCppValueTypeName(t fieldType) string {
+	switch t.Kind {
+	case fieldKindEnum:
+		return "E" + t.Name
+	case fieldKindStruct:
+		return "F" + t.Name
+	case fieldKindArray:
+		return fmt.Sprintf("TArray<%s>", toCppValueTypeName(*t.Elem))
+	case fieldKindOptional:
+		return fmt.Sprintf("TOptional<%s>", toCppValueTypeName(*t.Elem))
+	default:
+		return cppValueTypeNames[t.Name]
+	}
+}
+
 type fieldInfo struct {
 	FieldName, CppTypeName, CppFormat, CppParam string
 }
 
-func newFieldInfo(typeName, fieldName string) (fieldInfo, error) {
+// newFieldInfo Get field information. argName is the C++ expression of the field value.
+func newFieldInfo(schema Schema, typeName, fieldName, argName string) (fieldInfo, error) {
+	t, err := schema.parseType(typeName)
+	if err != nil {
+		return fieldInfo{}, err
+	}
+
+	switch {
+	case t.Kind == fieldKindEnum:
+		return fieldInfo{
+			FieldName:   fieldName,
+			CppTypeName: toCppValueTypeName(t),
+			CppFormat:   `"%s"`,
+			CppParam:    fmt.Sprintf("*StaticEnum<%s>()->GetNameStringByValue(static_cast<int64>(%s))", toCppValueTypeName(t), argName),
+		}, nil
+	case t.usesJSONHelper():
+		return fieldInfo{
+			FieldName:   fieldName,
+			CppTypeName: fmt.Sprintf("const %s&", toCppValueTypeName(t)),
+			CppFormat:   "%s",
+			CppParam:    fmt.Sprintf("*ToStructuredLogJson(%s)", argName),
+		}, nil
+	}
 
 	cppFormat, err := toCppTypeFormat(typeName)
 	if err != nil {
 		return fieldInfo{}, err
 	}
 
-	cppParam, err := toCppFormatParam(typeName, fieldName)
+	cppParam, err := toCppFormatParam(typeName, argName)
 	if err != nil {
 		return fieldInfo{}, err
 	}
@@ -177,7 +338,7 @@ func (b bodyField) Generate(structureName string) (string, string, string, error
 	return jsonStr.String(), funcName.String(), printParam.String(), nil
 }
 
-func genCppLogCode(w io.Writer, structureName string, info StructureInfo) error {
+func genCppLogCode(w io.Writer, schema Schema, structureName string, info StructureInfo) error {
 
 	// Sorting to ensure key order
 	bodyFieldNames := []string{}
@@ -189,7 +350,7 @@ func genCppLogCode(w io.Writer, structureName string, info StructureInfo) error
 	var bodyFieldData bodyField
 	for _, fieldName := range bodyFieldNames {
 		typeName := info.Body[fieldName]
-		fieldInfo, err := newFieldInfo(typeName, fieldName)
+		fieldInfo, err := newFieldInfo(schema, typeName, fieldName, fieldName)
 		if err != nil {
 			return err
 		}
@@ -198,32 +359,24 @@ func genCppLogCode(w io.Writer, structureName string, info StructureInfo) error
 
 	bodyJson, funcName, printParam, err := bodyFieldData.Generate(structureName)
 	if err != nil {
-		return nil
+		return err
 	}
 
-	bodyStrForMarshal := fmt.Sprintf("_REMOVEDOUBLEQUOTE_%s_REMOVEDOUBLEQUOTE_", bodyJson)
-	printFormat := map[string]interface{}{
-		"Meta": map[string]interface{}{
-			"Type": structureName,
-		},
-		"Body": map[string]interface{}{
-			"Meta": info.Meta,
-			"Body": bodyStrForMarshal,
-		},
+	metaJson, err := toCppJSONLiteral(info.Meta)
+	if err != nil {
+		return err
 	}
-
-	jsonData, err := json.Marshal(printFormat)
+	headerJson, err := toCppJSONLiteral(map[string]interface{}{
+		"Type":    structureName,
+		"Version": info.CurrentVersion(),
+	})
 	if err != nil {
 		return err
 	}
+	jsonStr := fmt.Sprintf(`{"Body":{"Body":%s,"Meta":%s},"Meta":%s}`, bodyJson, metaJson, headerJson)
 
-	// Remove escape sequence
-	jsonStr := string(jsonData)
-	jsonStr = strings.ReplaceAll(jsonStr, `\`, "")
-	jsonStr = strings.ReplaceAll(jsonStr, `"_REMOVEDOUBLEQUOTE_`, "")
-	jsonStr = strings.ReplaceAll(jsonStr, `_REMOVEDOUBLEQUOTE_"`, "")
-
-	_, err = fmt.Fprintf(w, `%s
+	// inline since the header is included by call sites of UE_LOG_<Name>
+	_, err = fmt.Fprintf(w, `inline %s
 {
 	return FString::Printf(TEXT(R"(%s%s%s)")%s);
 }
@@ -236,11 +389,240 @@ func genCppLogCode(w io.Writer, structureName string, info StructureInfo) error
 	return err
 }
 
+// toCppJSONLiteral Get JSON of value which can be embedded in format string of raw string literal
+//
+// ")" is escaped so that the raw string literal is not terminated by string values.
+func toCppJSONLiteral(value interface{}) (string, error) {
+	jsonData, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	jsonStr := strings.ReplaceAll(string(jsonData), ")", `\u0029`)
+	jsonStr = strings.ReplaceAll(jsonStr, "%", "%%")
+	return jsonStr, nil
+}
+
+// genCppEnumCode Generate UENUM of enum
+//
+// UENUM can not be declared in namespace.
+func genCppEnumCode(w io.Writer, enumName string, values EnumInfo) error {
+	_, err := fmt.Fprintf(w, "UENUM(BlueprintType)\nenum class E%s : uint8\n{\n", enumName)
+	if err != nil {
+		return err
+	}
+	for _, value := range values {
+		_, err = fmt.Fprintf(w, "\t%s,\n", value)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprint(w, "};\n\n")
+	return err
+}
+
+// genCppJSONHelperCode Generate ToStructuredLogJson overloads writing values of arrays, optionals and structs
+//
+// Overloads of element types are declared before templates since they are not found by ADL.
+func genCppJSONHelperCode(w io.Writer, schema Schema) error {
+	for _, typeName := range builtinTypeNames {
+		cppFormat, err := toCppTypeFormat(typeName)
+		if err != nil {
+			return err
+		}
+		cppParam, err := toCppFormatParam(typeName, "Value")
+		if err != nil {
+			return err
+		}
+		cppTypeName, err := toCppTypeName(typeName)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(w, `inline FString ToStructuredLogJson(%s Value)
+{
+	return FString::Printf(TEXT(R"(%s)"),%s);
+}
+
+`, cppTypeName, cppFormat, cppParam)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, enumName := range sortedKeys(schema.Enums) {
+		info, err := newFieldInfo(schema, enumName, "", "Value")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, `inline FString ToStructuredLogJson(%s Value)
+{
+	return FString::Printf(TEXT(R"(%s)"),%s);
+}
+
+`, info.CppTypeName, info.CppFormat, info.CppParam)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprint(w, `template <typename T>
+FString ToStructuredLogJson(const TArray<T>& Values)
+{
+	FString Json = TEXT("[");
+	for (int32 Index = 0; Index < Values.Num(); ++Index)
+	{
+		if (Index > 0)
+		{
+			Json += TEXT(",");
+		}
+		Json += ToStructuredLogJson(Values[Index]);
+	}
+	Json += TEXT("]");
+	return Json;
+}
+
+template <typename T>
+FString ToStructuredLogJson(const TOptional<T>& Value)
+{
+	return Value.IsSet() ? ToStructuredLogJson(Value.GetValue()) : FString(TEXT("null"));
+}
+
+`)
+	if err != nil {
+		return err
+	}
+
+	structNames, err := schema.sortedStructNames()
+	if err != nil {
+		return err
+	}
+	for _, structName := range structNames {
+		err := genCppStructCode(w, schema, structName, schema.Structs[structName])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// genCppStructCode Generate struct and its ToStructuredLogJson
+func genCppStructCode(w io.Writer, schema Schema, structName string, fields StructInfo) error {
+	_, err := fmt.Fprintf(w, "struct F%s\n{\n", structName)
+	if err != nil {
+		return err
+	}
+
+	var structFieldData bodyField
+	for _, fieldName := range sortedKeys(fields) {
+		t, err := schema.parseType(fields[fieldName])
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "\t%s %s;\n", toCppValueTypeName(t), fieldName)
+		if err != nil {
+			return err
+		}
+
+		info, err := newFieldInfo(schema, fields[fieldName], fieldName, "Value."+fieldName)
+		if err != nil {
+			return err
+		}
+		structFieldData = append(structFieldData, info)
+	}
+
+	jsonStr, _, printParam, err := structFieldData.Generate(structName)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, `};
+
+inline FString ToStructuredLogJson(const F%s& Value)
+{
+	return FString::Printf(TEXT(R"(%s)")%s);
+}
+
+`, structName, jsonStr, printParam)
+	return err
+}
+
+// CppConfig Options of generated C++ header
+type CppConfig struct {
+	Namespace string
+	// File name of generated header. e.g. sample.h
+	// Required if schema has enums since UENUM requires including <name>.generated.h.
+	// Generated source includes the header with this name.
+	HeaderName string
+	// Log categories declared out of generated code. e.g. LogMyGame declared by the game module
+	// They are used without being declared and defined. Categories of the engine such as LogTemp are always regarded as external.
+	ExternalCategories []string
+}
+
+// engineLogCategories Log categories declared by CoreGlobals.h and EngineLogs.h of Unreal Engine
+var engineLogCategories = map[string]bool{
+	"LogHAL": true, "LogSerialization": true, "LogUnrealMath": true, "LogUnrealMatrix": true, "LogMemory": true,
+	"LogProfilingDebugging": true, "LogCore": true, "LogOutputDevice": true, "LogSHA": true, "LogStats": true,
+	"LogStreaming": true, "LogInit": true, "LogExit": true, "LogExec": true, "LogScript": true, "LogLocalization": true,
+	"LogLongPackageNames": true, "LogProcess": true, "LogLoad": true, "LogTemp": true,
+	"LogPath": true, "LogPhysics": true, "LogBlueprint": true, "LogBlueprintUserMessages": true, "LogAnimation": true,
+	"LogRootMotion": true, "LogLevel": true, "LogSkeletalMesh": true, "LogStaticMesh": true, "LogNet": true,
+	"LogRep": true, "LogNetPlayerMovement": true, "LogNetTraffic": true, "LogRepTraffic": true, "LogNetFastTArray": true,
+	"LogNetDormancy": true, "LogSkeletalControl": true, "LogSubtitle": true, "LogTexture": true, "LogPlayerManagement": true,
+	"LogSecurity": true, "LogEngineSessionManager": true, "LogViewport": true,
+}
+
+// ownedLogCategories Get default verbosity of log categories which generated code declares and defines
+func (c CppConfig) ownedLogCategories(schema Schema) map[string]string {
+	categories := logCategories(schema)
+	for category := range categories {
+		if engineLogCategories[category] {
+			delete(categories, category)
+		}
+	}
+	for _, category := range c.ExternalCategories {
+		delete(categories, category)
+	}
+	return categories
+}
+
 func GenCppFile(w io.Writer, namespace string, infoList StructureInfoList) error {
+	return GenCppSchemaFile(w, CppConfig{Namespace: namespace}, Schema{List: infoList})
+}
+
+// GenCppSchemaFile Generate C++ header of structures with enums and structs used by them
+func GenCppSchemaFile(w io.Writer, config CppConfig, schema Schema) error {
+	if len(schema.Enums) != 0 && config.HeaderName == "" {
+		return errors.New("GenCppSchemaFile: HeaderName is required for enums")
+	}
+
 	fmt.Fprintln(w, "// Code generated by structuregen. DO NOT EDIT.")
 	fmt.Fprintln(w, "#pragma once")
 	fmt.Fprintln(w, `#include "CoreMinimal.h"`)
+	if len(schema.Enums) != 0 {
+		fmt.Fprintf(w, "#include \"%s.generated.h\"\n\n", strings.TrimSuffix(config.HeaderName, filepath.Ext(config.HeaderName)))
+	}
+
+	for _, enumName := range sortedKeys(schema.Enums) {
+		err := genCppEnumCode(w, enumName, schema.Enums[enumName])
+		if err != nil {
+			return err
+		}
+	}
+
+	// Log categories are declared out of namespace since UE_LOG pastes tokens of category name
+	err := genCppLogCategoryDeclarations(w, config, schema)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, cppJSONValueHelperCode)
+	if err != nil {
+		return err
+	}
 
+	namespace := config.Namespace
 	if namespace != "" {
 		_, err := fmt.Fprintf(w, "namespace %s {\n\n", namespace)
 		if err != nil {
@@ -248,8 +630,15 @@ func GenCppFile(w io.Writer, namespace string, infoList StructureInfoList) error
 		}
 	}
 
-	for structureName, info := range infoList {
-		err := genCppLogCode(w, structureName, info)
+	if schema.usesJSONHelper() {
+		err := genCppJSONHelperCode(w, schema)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, structureName := range sortedKeys(schema.List) {
+		err := genCppLogCode(w, schema, structureName, schema.List[structureName])
 		if err != nil {
 			return err
 		}
@@ -262,5 +651,123 @@ func GenCppFile(w io.Writer, namespace string, infoList StructureInfoList) error
 		}
 	}
 
+	return genCppLogMacros(w, namespace, schema)
+}
+
+// cppVerbosityOrder Verbosities of UE_LOG from the least verbose
+//
+// Fatal is not included since UE_LOG with Fatal crashes the game.
+var cppVerbosityOrder = []string{"Error", "Warning", "Display", "Log", "Verbose", "VeryVerbose"}
+
+func verbosityLevel(verbosity string) int {
+	for i, v := range cppVerbosityOrder {
+		if v == verbosity {
+			return i
+		}
+	}
+	return -1
+}
+
+// logCategories Get default verbosity of log categories used by structures
+//
+// Default verbosity is Log or the most verbose one of the structures so that their logs are output by default.
+func logCategories(schema Schema) map[string]string {
+	categories := map[string]string{}
+	for _, info := range schema.List {
+		if info.Category == "" {
+			continue
+		}
+		verbosity, ok := categories[info.Category]
+		if !ok {
+			verbosity = "Log"
+		}
+		if verbosityLevel(info.LogVerbosity()) > verbosityLevel(verbosity) {
+			verbosity = info.LogVerbosity()
+		}
+		categories[info.Category] = verbosity
+	}
+	return categories
+}
+
+func genCppLogCategoryDeclarations(w io.Writer, config CppConfig, schema Schema) error {
+	categories := config.ownedLogCategories(schema)
+	for _, category := range sortedKeys(categories) {
+		_, err := fmt.Fprintf(w, "DECLARE_LOG_CATEGORY_EXTERN(%s, %s, All);\n", category, categories[category])
+		if err != nil {
+			return err
+		}
+	}
+	if len(categories) != 0 {
+		_, err := fmt.Fprintln(w)
+		return err
+	}
+	return nil
+}
+
+// cppLogEnabledCode Default of STRUCTURED_LOG_ENABLED. Structured logs are compiled out in shipping builds.
+const cppLogEnabledCode = `#ifndef STRUCTURED_LOG_ENABLED
+#define STRUCTURED_LOG_ENABLED (!NO_LOGGING && !UE_BUILD_SHIPPING)
+#endif
+`
+
+// genCppLogMacros Generate UE_LOG_<Name> macros of structures with log category
+//
+// Define STRUCTURED_LOG_ENABLED as 1 before including the header to keep the macros in shipping builds.
+func genCppLogMacros(w io.Writer, namespace string, schema Schema) error {
+	names := []string{}
+	for _, structureName := range sortedKeys(schema.List) {
+		if schema.List[structureName].Category != "" {
+			names = append(names, structureName)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	_, err := fmt.Fprint(w, "\n"+cppLogEnabledCode+"\n#if STRUCTURED_LOG_ENABLED\n")
+	if err != nil {
+		return err
+	}
+	for _, structureName := range names {
+		info := schema.List[structureName]
+		if verbosityLevel(info.LogVerbosity()) < 0 {
+			return fmt.Errorf("genCppLogMacros: Name: %s Invalid verbosity %s", structureName, info.LogVerbosity())
+		}
+		_, err = fmt.Fprintf(w, "#define UE_LOG_%s(...) UE_LOG(%s, %s, TEXT(\"%%s\"), *%s::Log%s(__VA_ARGS__))\n", structureName, info.Category, info.LogVerbosity(), namespace, structureName)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintln(w, "#else")
+	if err != nil {
+		return err
+	}
+	for _, structureName := range names {
+		_, err = fmt.Fprintf(w, "#define UE_LOG_%s(...)\n", structureName)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintln(w, "#endif")
+	return err
+}
+
+// GenCppSourceFile Generate C++ source defining log categories declared in header generated by GenCppSchemaFile
+func GenCppSourceFile(w io.Writer, config CppConfig, schema Schema) error {
+	if config.HeaderName == "" {
+		return errors.New("GenCppSourceFile: HeaderName is required")
+	}
+
+	_, err := fmt.Fprintf(w, "// Code generated by structuregen. DO NOT EDIT.\n#include \"%s\"\n\n", config.HeaderName)
+	if err != nil {
+		return err
+	}
+
+	for _, category := range sortedKeys(config.ownedLogCategories(schema)) {
+		_, err := fmt.Fprintf(w, "DEFINE_LOG_CATEGORY(%s);\n", category)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,291 @@
+package gen_test
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+	"github.com/y-akahori-ramen/ueLogHandler/gen"
+)
+
+// cppJSONString Same escaping as StructuredLogJsonString of generated C++
+func cppJSONString(value string) string {
+	var json strings.Builder
+	json.WriteByte('"')
+	for _, c := range value {
+		switch c {
+		case '"':
+			json.WriteString(`\"`)
+		case '\\':
+			json.WriteString(`\\`)
+		case '\n':
+			json.WriteString(`\n`)
+		case '\r':
+			json.WriteString(`\r`)
+		case '\t':
+			json.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				fmt.Fprintf(&json, `\u%04x`, c)
+			} else {
+				json.WriteRune(c)
+			}
+		}
+	}
+	json.WriteByte('"')
+	return json.String()
+}
+
+// cppJSONDouble Same output as StructuredLogJsonDouble of generated C++
+func cppJSONDouble(value float64) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return "null"
+	}
+	return strconv.FormatFloat(value, 'g', 17, 64)
+}
+
+// cppPrintf Format like FString::Printf with arguments already converted to strings
+func cppPrintf(format string, args ...string) string {
+	directive := regexp.MustCompile(`%%|%(?:ll)?[dus]`)
+	i := 0
+	return directive.ReplaceAllStringFunc(format, func(match string) string {
+		if match == "%%" {
+			return "%"
+		}
+		arg := args[i]
+		i++
+		return arg
+	})
+}
+
+// cppFormatString Get format string of logging function in generated C++
+func cppFormatString(cpp, structureName string) (string, bool) {
+	pattern := regexp.MustCompile(`FString Log` + structureName + `\(.*\)\n\{\n\treturn FString::Printf\(TEXT\(R"\((.*)\)"\)`)
+	matches := pattern.FindStringSubmatch(cpp)
+	if matches == nil {
+		return "", false
+	}
+	return matches[1], true
+}
+
+func TestGenCppJSONEscaping(t *testing.T) {
+	schema, err := gen.ReadSchemaYAML(strings.NewReader(`
+structures:
+  list:
+    Escape:
+      Meta:
+        Tag: 'He said "hi" (100%) \ done'
+      Body:
+        Name: string
+        Rate: double
+        Count: int32
+        Position: vector3`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var cpp bytes.Buffer
+	if !assert.NoError(t, gen.GenCppSchemaFile(&cpp, gen.CppConfig{}, schema)) {
+		return
+	}
+	format, ok := cppFormatString(cpp.String(), "Escape")
+	if !assert.True(t, ok, cpp.String()) {
+		return
+	}
+	assert.NotContains(t, format, `)"`, "raw string literal must not be terminated")
+
+	// cppJSONString and cppJSONDouble mirror the generated helpers, so the helpers themselves are checked
+	helperCodes := []string{
+		`case TEXT('"'):` + "\n\t\t\t" + `Json += TEXT("\\\"");`,
+		`case TEXT('\\'):` + "\n\t\t\t" + `Json += TEXT("\\\\");`,
+		`case TEXT('\n'):` + "\n\t\t\t" + `Json += TEXT("\\n");`,
+		`case TEXT('\r'):` + "\n\t\t\t" + `Json += TEXT("\\r");`,
+		`case TEXT('\t'):` + "\n\t\t\t" + `Json += TEXT("\\t");`,
+		`if (Char < 0x20)` + "\n\t\t\t{\n\t\t\t\t" + `Json += FString::Printf(TEXT("\\u%04x"), static_cast<uint32>(Char));`,
+		`Json.AppendChar(Char);`,
+		`if (!FMath::IsFinite(Value))` + "\n\t{\n\t\t" + `return TEXT("null");`,
+		`return FString::Printf(TEXT("%.9g"), Value);`,
+		`return FString::Printf(TEXT("%.17g"), Value);`,
+	}
+	for _, code := range helperCodes {
+		assert.Contains(t, cpp.String(), code)
+	}
+
+	type meta struct {
+		Tag string
+	}
+	type body struct {
+		Count    int32
+		Name     string
+		Position ueloghandler.FVector
+		Rate     float64
+	}
+
+	type testCase struct {
+		name     string
+		rate     float64
+		position ueloghandler.FVector
+		wantRate float64
+		// NaN and infinity are written as null
+		wantNull bool
+	}
+	testCases := []testCase{
+		{name: `quote " backslash \ slash /`, rate: 0.1, wantRate: 0.1},
+		{name: "new line\nreturn\r\ntab\tcontrol\x01\x1f", rate: math.MaxFloat64, wantRate: math.MaxFloat64},
+		{name: "unicode ダメージ 🎮 _END_STRUCTURED_", rate: math.SmallestNonzeroFloat64, wantRate: math.SmallestNonzeroFloat64},
+		{name: "", rate: math.NaN(), position: ueloghandler.FVector{X: math.Inf(1), Y: math.Inf(-1), Z: -0.5}, wantRate: 0, wantNull: true},
+	}
+
+	for i := range testCases {
+		testCase := testCases[i]
+		t.Run(fmt.Sprintf("Case%d", i), func(t *testing.T) {
+			assert := assert.New(t)
+
+			logStr := cppPrintf(format,
+				"7",
+				cppJSONString(testCase.name),
+				cppJSONDouble(testCase.position.X), cppJSONDouble(testCase.position.Y), cppJSONDouble(testCase.position.Z),
+				cppJSONDouble(testCase.rate),
+			)
+
+			var actual []ueloghandler.TStructuredData[meta, body]
+			newHandler := func(strict bool) *ueloghandler.StructuredLogHandler {
+				handler := ueloghandler.NewStructuredLogHandlerWithConfig(ueloghandler.StructuredLogHandlerConfig{Strict: strict})
+				handler.AddHandler(ueloghandler.NewStructuredLogDataHandler("Escape", func(data ueloghandler.TStructuredData[meta, body], log ueloghandler.Log) error {
+					actual = append(actual, data)
+					return nil
+				}))
+				return handler
+			}
+
+			handler := newHandler(true)
+			if testCase.wantNull {
+				// Strict mode rejects null instead of decoding it as zero
+				var validationErr *ueloghandler.ValidationError
+				if assert.ErrorAs(handler.HandleLog(ueloghandler.Log{Log: logStr}), &validationErr) {
+					assert.Equal("Body.Position.X", validationErr.Field)
+				}
+				handler = newHandler(false)
+			}
+			if !assert.NoError(handler.HandleLog(ueloghandler.Log{Log: logStr}), logStr) {
+				return
+			}
+
+			wantPosition := testCase.position
+			// null is decoded as zero in non-strict mode
+			if math.IsInf(wantPosition.X, 0) {
+				wantPosition.X = 0
+			}
+			if math.IsInf(wantPosition.Y, 0) {
+				wantPosition.Y = 0
+			}
+			assert.Equal([]ueloghandler.TStructuredData[meta, body]{{
+				Meta: meta{Tag: `He said "hi" (100%) \ done`},
+				Body: body{Count: 7, Name: testCase.name, Position: wantPosition, Rate: testCase.wantRate},
+			}}, actual)
+		})
+	}
+}
+
+func TestGenCppInt64(t *testing.T) {
+	schema, err := gen.ReadSchemaYAML(strings.NewReader(`
+structures:
+  list:
+    Id:
+      Body:
+        Signed: int64
+        Unsigned: uint64
+        Values: array<uint64>`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var cpp bytes.Buffer
+	if !assert.NoError(t, gen.GenCppSchemaFile(&cpp, gen.CppConfig{}, schema)) {
+		return
+	}
+	cppStr := cpp.String()
+	assert.Contains(t, cppStr, "static_cast<long long>(Signed),static_cast<unsigned long long>(Unsigned)")
+	assert.Contains(t, cppStr, `return FString::Printf(TEXT(R"(%llu)"),static_cast<unsigned long long>(Value));`)
+	assert.NotContains(t, cppStr, "%ld")
+	assert.NotContains(t, cppStr, "%lu")
+
+	format, ok := cppFormatString(cppStr, "Id")
+	if !assert.True(t, ok, cppStr) {
+		return
+	}
+	assert.Contains(t, format, `{"Signed":%lld,"Unsigned":%llu,"Values":%s}`)
+
+	type body struct {
+		Signed   int64
+		Unsigned uint64
+		Values   []uint64
+	}
+
+	testCases := []body{
+		{Signed: math.MaxInt64, Unsigned: math.MaxUint64, Values: []uint64{math.MaxUint64, math.MaxUint64 - 1, 1 << 53, 1<<53 + 1}},
+		{Signed: math.MinInt64, Unsigned: 0, Values: []uint64{}},
+	}
+
+	for i := range testCases {
+		testCase := testCases[i]
+		t.Run(fmt.Sprintf("Case%d", i), func(t *testing.T) {
+			assert := assert.New(t)
+
+			values := []string{}
+			for _, value := range testCase.Values {
+				values = append(values, strconv.FormatUint(value, 10))
+			}
+			logStr := cppPrintf(format,
+				strconv.FormatInt(testCase.Signed, 10),
+				strconv.FormatUint(testCase.Unsigned, 10),
+				"["+strings.Join(values, ",")+"]",
+			)
+
+			var actual []body
+			handler := ueloghandler.NewStructuredLogHandlerWithConfig(ueloghandler.StructuredLogHandlerConfig{Strict: true})
+			handler.AddHandler(ueloghandler.NewStructuredLogDataHandler("Id", func(data ueloghandler.TStructuredData[struct{}, body], log ueloghandler.Log) error {
+				actual = append(actual, data.Body)
+				return nil
+			}))
+			if assert.NoError(handler.HandleLog(ueloghandler.Log{Log: logStr}), logStr) {
+				assert.Equal([]body{testCase}, actual)
+			}
+		})
+	}
+}
+
+func TestGenCppJSONHelpersGuard(t *testing.T) {
+	assert := assert.New(t)
+
+	schema, err := gen.ReadSchemaYAML(strings.NewReader(`
+structures:
+  list:
+    Damage:
+      Body:
+        Name: string`))
+	if !assert.NoError(err) {
+		return
+	}
+
+	var cpp bytes.Buffer
+	if !assert.NoError(gen.GenCppSchemaFile(&cpp, gen.CppConfig{Namespace: "structuredLog"}, schema)) {
+		return
+	}
+	cppStr := cpp.String()
+
+	// Helpers are defined out of namespace so that headers of other namespaces can share them
+	guard := strings.Index(cppStr, "#ifndef STRUCTUREDLOG_JSON_HELPERS\n#define STRUCTUREDLOG_JSON_HELPERS\n")
+	helper := strings.Index(cppStr, "inline FString StructuredLogJsonString(")
+	guardEnd := strings.Index(cppStr, "#endif\n")
+	namespace := strings.Index(cppStr, "namespace structuredLog {")
+	assert.True(0 <= guard && guard < helper && helper < guardEnd && guardEnd < namespace, cppStr)
+	assert.Equal(1, strings.Count(cppStr, "inline FString StructuredLogJsonString("))
+	assert.Contains(cppStr, "*StructuredLogJsonString(Name)")
+}
@@ -10,7 +10,24 @@ import (
 
 const handlerPackageName = "github.com/y-akahori-ramen/ueLogHandler"
 
-func appendGoType(statement *jen.Statement, typename string) error {
+func appendGoType(statement *jen.Statement, schema Schema, typename string) error {
+	t, err := schema.parseType(typename)
+	if err != nil {
+		return err
+	}
+
+	switch t.Kind {
+	case fieldKindArray:
+		statement.Index()
+		return appendGoType(statement, schema, t.Elem.typeName())
+	case fieldKindOptional:
+		statement.Op("*")
+		return appendGoType(statement, schema, t.Elem.typeName())
+	case fieldKindEnum, fieldKindStruct:
+		statement.Id(t.Name)
+		return nil
+	}
+
 	switch typename {
 	case "float":
 		fallthrough
@@ -32,21 +49,58 @@ func appendGoType(statement *jen.Statement, typename string) error {
 	case "bool":
 		statement.Bool()
 		return nil
-	case "string":
+	case "string", "name", "text", "guid":
 		statement.String()
 		return nil
+	case "datetime":
+		statement.Qual("time", "Time")
+		return nil
 	case "vector2":
 		statement.Qual(handlerPackageName, "FVector2D")
 		return nil
 	case "vector3":
 		statement.Qual(handlerPackageName, "FVector")
 		return nil
+	case "rotator":
+		statement.Qual(handlerPackageName, "FRotator")
+		return nil
+	case "quat":
+		statement.Qual(handlerPackageName, "FQuat")
+		return nil
+	case "linearcolor":
+		statement.Qual(handlerPackageName, "FLinearColor")
+		return nil
 	default:
 		return fmt.Errorf("GetGoType:Invalid typename:%s", typename)
 	}
 }
 
-func genStructureCode(structureName string, info StructureInfo) ([]jen.Code, error) {
+// genEnumCode Generate string type and constants of enum values
+func genEnumCode(enumName string, values EnumInfo) []jen.Code {
+	consts := []jen.Code{}
+	for _, value := range values {
+		consts = append(consts, jen.Id(enumName+value).Id(enumName).Op("=").Lit(value))
+	}
+	return []jen.Code{
+		jen.Type().Id(enumName).String(),
+		jen.Const().Defs(consts...),
+	}
+}
+
+func genStructCode(schema Schema, structName string, fields StructInfo) (jen.Code, error) {
+	structFields := []jen.Code{}
+	for _, fieldName := range sortedKeys(fields) {
+		field := jen.Id(fieldName)
+		err := appendGoType(field, schema, fields[fieldName])
+		if err != nil {
+			return nil, err
+		}
+		structFields = append(structFields, field)
+	}
+	return jen.Type().Id(structName).Struct(structFields...), nil
+}
+
+func genStructureCode(schema Schema, structureName string, info StructureInfo) ([]jen.Code, error) {
 	code := []jen.Code{}
 
 	metaName := fmt.Sprintf("%sMeta", structureName)
@@ -72,9 +126,9 @@ func genStructureCode(structureName string, info StructureInfo) ([]jen.Code, err
 
 	bodyName := fmt.Sprintf("%sBody", structureName)
 	bodyFields := []jen.Code{}
-	for fieldName, typeName := range info.Body {
+	for _, fieldName := range sortedKeys(info.Body) {
 		field := jen.Id(fieldName)
-		err := appendGoType(field, typeName)
+		err := appendGoType(field, schema, info.Body[fieldName])
 		if err != nil {
 			return nil, err
 		}
@@ -120,6 +174,50 @@ func genStructureCode(structureName string, info StructureInfo) ([]jen.Code, err
 	)
 	code = append(code, logHandlerFuncHandle)
 
+	schemaName := fmt.Sprintf("%sSchema", structureName)
+	structureSchema := jen.Var().Id(schemaName).Op("=").Qual(handlerPackageName, "StructureSchema").Values(jen.Dict{
+		jen.Id("Meta"): jen.Index().String().Values(sortedLits(info.Meta)...),
+		jen.Id("Body"): jen.Index().String().Values(sortedLits(info.Body)...),
+	})
+	code = append(code, structureSchema)
+
+	logHandlerFuncHandleStrict := jen.Func().Params(
+		jen.Id("h").Id(logHanderTypeNameRef),
+	).Id("HandleStrict").Params(
+		jen.Id("json").String(),
+		jen.Id("log").Qual(handlerPackageName, "Log"),
+	).Error().Block(
+		jen.List(jen.Id("data"), jen.Err()).Op(":=").Qual(handlerPackageName, "JSONToStructuredDataStrict").Types(jen.Id(metaName), jen.Id(bodyName)).Call(jen.Lit(structureName), jen.Id(schemaName), jen.Id("json")),
+		jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+		jen.Return(jen.Id("h.f").Call(jen.Id(dataName).Call(jen.Id("data")), jen.Id("log"))),
+	)
+	code = append(code, logHandlerFuncHandleStrict)
+
+	historyName := fmt.Sprintf("%sHistory", structureName)
+	historyValues, err := historyValues(info, schemaName)
+	if err != nil {
+		return nil, err
+	}
+	history := jen.Var().Id(historyName).Op("=").Qual(handlerPackageName, "StructureHistory").Values(historyValues)
+	code = append(code, history)
+
+	logHandlerFuncVersion := jen.Func().Params(
+		jen.Id("h").Id(logHanderTypeNameRef),
+	).Id("Version").Params().Int().Block(
+		jen.Return(jen.Lit(info.CurrentVersion())),
+	)
+	code = append(code, logHandlerFuncVersion)
+
+	logHandlerFuncUpgrade := jen.Func().Params(
+		jen.Id("h").Id(logHanderTypeNameRef),
+	).Id("Upgrade").Params(
+		jen.Id("version").Int(),
+		jen.Id("json").String(),
+	).Params(jen.String(), jen.Error()).Block(
+		jen.Return(jen.Qual(handlerPackageName, "UpgradeStructuredJSON").Call(jen.Lit(structureName), jen.Id(historyName), jen.Id("version"), jen.Id("json"))),
+	)
+	code = append(code, logHandlerFuncUpgrade)
+
 	newLogHandlerFunc := jen.Func().Id(fmt.Sprintf("New%s", logHanderTypeName)).Params(
 		jen.Id("f").Id(dataHandlerFuncName),
 	).Qual(handlerPackageName, "StructuredLogDataHandler").Block(
@@ -130,13 +228,106 @@ func genStructureCode(structureName string, info StructureInfo) ([]jen.Code, err
 	return code, nil
 }
 
+// historyValues Get fields of StructureHistory literal
+func historyValues(info StructureInfo, schemaName string) (jen.Dict, error) {
+	meta, err := goValue(info.Meta)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []jen.Code{}
+	for _, change := range info.Changes {
+		renames := jen.Dict{}
+		for _, name := range sortedKeys(change.Renames) {
+			renames[jen.Lit(name)] = jen.Lit(change.Renames[name])
+		}
+		defaults, err := goValue(change.Defaults)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, jen.Values(jen.Dict{
+			jen.Id("Version"):  jen.Lit(change.Version),
+			jen.Id("Renames"):  jen.Map(jen.String()).String().Values(renames),
+			jen.Id("Defaults"): defaults,
+		}))
+	}
+
+	return jen.Dict{
+		jen.Id("Version"): jen.Lit(info.CurrentVersion()),
+		jen.Id("Schema"):  jen.Id(schemaName),
+		jen.Id("Meta"):    meta,
+		jen.Id("Changes"): jen.Index().Qual(handlerPackageName, "StructureChange").Values(changes...),
+	}, nil
+}
+
+// goValue Get literal of value decoded from YAML
+func goValue(value interface{}) (jen.Code, error) {
+	switch v := value.(type) {
+	case string, float64, int, bool:
+		return jen.Lit(v), nil
+	case map[string]interface{}:
+		dict := jen.Dict{}
+		for _, key := range sortedKeys(v) {
+			code, err := goValue(v[key])
+			if err != nil {
+				return nil, err
+			}
+			dict[jen.Lit(key)] = code
+		}
+		return jen.Map(jen.String()).Interface().Values(dict), nil
+	case []interface{}:
+		values := make([]jen.Code, 0, len(v))
+		for _, element := range v {
+			code, err := goValue(element)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, code)
+		}
+		return jen.Index().Interface().Values(values...), nil
+	case nil:
+		return jen.Nil(), nil
+	default:
+		return nil, fmt.Errorf("goValue: Invalid value:%v Type:%s", value, reflect.TypeOf(value))
+	}
+}
+
+// sortedLits Get sorted keys of map as literals
+func sortedLits[T any](m map[string]T) []jen.Code {
+	lits := []jen.Code{}
+	for _, key := range sortedKeys(m) {
+		lits = append(lits, jen.Lit(key))
+	}
+	return lits
+}
+
 func GenGoFile(w io.Writer, packageName string, infoList StructureInfoList) error {
+	return GenGoSchemaFile(w, packageName, Schema{List: infoList})
+}
+
+// GenGoSchemaFile Generate Go file of structures with enums and structs used by them
+func GenGoSchemaFile(w io.Writer, packageName string, schema Schema) error {
 	f := jen.NewFile(packageName)
 	f.ImportAlias(handlerPackageName, "ueloghandler")
 
 	f.HeaderComment("Code generated by structuregen. DO NOT EDIT.")
-	for structureName, info := range infoList {
-		codes, err := genStructureCode(structureName, info)
+	for _, enumName := range sortedKeys(schema.Enums) {
+		for _, code := range genEnumCode(enumName, schema.Enums[enumName]) {
+			f.Add(code)
+		}
+	}
+
+	for _, structName := range sortedKeys(schema.Structs) {
+		code, err := genStructCode(schema, structName, schema.Structs[structName])
+		if err != nil {
+			return err
+		}
+		f.Add(code)
+	}
+
+	for _, structureName := range sortedKeys(schema.List) {
+		info := schema.List[structureName]
+		codes, err := genStructureCode(schema, structureName, info)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,151 @@
+package gen_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/y-akahori-ramen/ueLogHandler/gen"
+)
+
+func TestGenCppLogCategory(t *testing.T) {
+	assert := assert.New(t)
+
+	schema, err := gen.ReadSchemaYAML(strings.NewReader(`
+structures:
+  list:
+    Damage:
+      Category: LogCombat
+      Verbosity: Display
+      Body:
+        Amount: int32
+    Heal:
+      Category: LogCombat
+      Verbosity: Verbose
+      Body:
+        Amount: int32
+    Spawn:
+      Category: LogWorld
+      Body:
+        Count: int32
+    Other:
+      Body:
+        Count: int32`))
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal("Display", schema.List["Damage"].LogVerbosity())
+	assert.Equal("Log", schema.List["Spawn"].LogVerbosity())
+
+	config := gen.CppConfig{Namespace: "structuredLog", HeaderName: "sample.h"}
+	var header bytes.Buffer
+	if !assert.NoError(gen.GenCppSchemaFile(&header, config, schema)) {
+		return
+	}
+	headerStr := header.String()
+	// Default verbosity of category outputs all structures using it
+	assert.Contains(headerStr, "DECLARE_LOG_CATEGORY_EXTERN(LogCombat, Verbose, All);\nDECLARE_LOG_CATEGORY_EXTERN(LogWorld, Log, All);\n\n#ifndef STRUCTUREDLOG_JSON_HELPERS")
+	assert.Contains(headerStr, "#define STRUCTURED_LOG_ENABLED (!NO_LOGGING && !UE_BUILD_SHIPPING)")
+	assert.Contains(headerStr, `#if STRUCTURED_LOG_ENABLED
+#define UE_LOG_Damage(...) UE_LOG(LogCombat, Display, TEXT("%s"), *structuredLog::LogDamage(__VA_ARGS__))
+#define UE_LOG_Heal(...) UE_LOG(LogCombat, Verbose, TEXT("%s"), *structuredLog::LogHeal(__VA_ARGS__))
+#define UE_LOG_Spawn(...) UE_LOG(LogWorld, Log, TEXT("%s"), *structuredLog::LogSpawn(__VA_ARGS__))
+#else
+#define UE_LOG_Damage(...)
+#define UE_LOG_Heal(...)
+#define UE_LOG_Spawn(...)
+#endif
+`)
+	assert.NotContains(headerStr, "UE_LOG_Other")
+	assert.Contains(headerStr, "inline FString LogDamage(int32 Amount)")
+
+	var source bytes.Buffer
+	assert.NoError(gen.GenCppSourceFile(&source, config, schema))
+	assert.Equal("// Code generated by structuregen. DO NOT EDIT.\n#include \"sample.h\"\n\nDEFINE_LOG_CATEGORY(LogCombat);\nDEFINE_LOG_CATEGORY(LogWorld);\n", source.String())
+
+	invalidYAMLs := []string{
+		// Verbosity without category
+		`
+structures:
+  list:
+    Damage:
+      Verbosity: Display
+      Body:
+        Amount: int32`,
+		// Unknown verbosity
+		`
+structures:
+  list:
+    Damage:
+      Category: LogCombat
+      Verbosity: Info
+      Body:
+        Amount: int32`,
+		// Fatal crashes the game
+		`
+structures:
+  list:
+    Damage:
+      Category: LogCombat
+      Verbosity: Fatal
+      Body:
+        Amount: int32`,
+		// Category conflicts with logging function LogDamage
+		`
+structures:
+  list:
+    Damage:
+      Category: LogDamage
+      Body:
+        Amount: int32`,
+	}
+	for _, yaml := range invalidYAMLs {
+		_, err := gen.ReadSchemaYAML(strings.NewReader(yaml))
+		assert.Error(err, yaml)
+	}
+
+	// Schema not read from file is also checked
+	fatalSchema := gen.Schema{List: gen.StructureInfoList{"Damage": {Category: "LogCombat", Verbosity: "Fatal", Body: map[string]string{"Amount": "int32"}}}}
+	assert.Error(gen.GenCppSchemaFile(&bytes.Buffer{}, config, fatalSchema))
+}
+
+func TestGenCppExternalLogCategory(t *testing.T) {
+	assert := assert.New(t)
+
+	schema, err := gen.ReadSchemaYAML(strings.NewReader(`
+structures:
+  list:
+    Damage:
+      Category: LogTemp
+      Body:
+        Amount: int32
+    Spawn:
+      Category: LogMyGame
+      Body:
+        Amount: int32
+    Heal:
+      Category: LogCombat
+      Body:
+        Amount: int32`))
+	if !assert.NoError(err) {
+		return
+	}
+
+	config := gen.CppConfig{Namespace: "structuredLog", HeaderName: "sample.h", ExternalCategories: []string{"LogMyGame"}}
+	var header bytes.Buffer
+	if !assert.NoError(gen.GenCppSchemaFile(&header, config, schema)) {
+		return
+	}
+	headerStr := header.String()
+	// Engine categories and external categories are used without declaring
+	assert.NotContains(headerStr, "DECLARE_LOG_CATEGORY_EXTERN(LogTemp")
+	assert.NotContains(headerStr, "DECLARE_LOG_CATEGORY_EXTERN(LogMyGame")
+	assert.Contains(headerStr, "DECLARE_LOG_CATEGORY_EXTERN(LogCombat, Log, All);")
+	assert.Contains(headerStr, `#define UE_LOG_Damage(...) UE_LOG(LogTemp, Log, TEXT("%s"), *structuredLog::LogDamage(__VA_ARGS__))`)
+	assert.Contains(headerStr, `#define UE_LOG_Spawn(...) UE_LOG(LogMyGame, Log, TEXT("%s"), *structuredLog::LogSpawn(__VA_ARGS__))`)
+
+	var source bytes.Buffer
+	assert.NoError(gen.GenCppSourceFile(&source, config, schema))
+	assert.Equal("// Code generated by structuregen. DO NOT EDIT.\n#include \"sample.h\"\n\nDEFINE_LOG_CATEGORY(LogCombat);\n", source.String())
+}
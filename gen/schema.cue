@@ -1,14 +1,33 @@
 metaValue: bool | string | number
-bodyData: "float" | "double" | "int32" | "uint32" | "int64" | "uint64" | "bool" | "vector2" | "vector3" | "string"
+builtinType: "float" | "double" | "int32" | "uint32" | "int64" | "uint64" | "bool" | "vector2" | "vector3" | "string" | "rotator" | "quat" | "linearcolor" | "name" | "text" | "guid" | "datetime"
 keyName: =~"^[A-Z][A-Za-z0-9_]+$"
+// Builtin type, enum or struct
+typeName: builtinType | keyName
+bodyData: typeName | =~"^(array|optional)<[A-Za-z][A-Za-z0-9_]*>$"
+enumValue: =~"^[A-Za-z][A-Za-z0-9_]*$"
+// Fatal is not allowed since UE_LOG with Fatal crashes the game
+verbosity: "Error" | "Warning" | "Display" | "Log" | "Verbose" | "VeryVerbose"
+
+#Change: {
+	Version: int & >=2
+	Renames?: [keyName]: keyName
+	Defaults?: [keyName]: _
+}
 
 #Structure: {
+	Version?: int & >=1
+	// Log category and verbosity of UE_LOG_<Name> macro
+	Category?:  =~"^[A-Za-z_][A-Za-z0-9_]*$"
+	Verbosity?: verbosity
 	Meta: [keyName]: metaValue
 	Body: [keyName]: bodyData
+	Changes?: [...#Change]
 }
 
 #Structures: {
+	enums?: [keyName]: [...enumValue]
+	structs?: [keyName]: [keyName]: bodyData
 	list: [keyName]: #Structure
 }
 
-structures: #Structures
\ No newline at end of file
+structures: #Structures
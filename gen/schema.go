@@ -13,7 +13,9 @@ import (
 )
 
 type structureList struct {
-	List StructureInfoList `json:"list"`
+	Enums   map[string]EnumInfo   `json:"enums"`
+	Structs map[string]StructInfo `json:"structs"`
+	List    StructureInfoList     `json:"list"`
 }
 
 type structureFile struct {
@@ -52,39 +54,74 @@ func readYAML(ctx *cue.Context, r io.Reader) (cue.Value, error) {
 	return value, nil
 }
 
+// ReadStructureInfoYAML Read structures of schema file
+//
+// Use ReadSchemaYAML to get enums and structs used by the structures.
 func ReadStructureInfoYAML(r io.Reader) (StructureInfoList, error) {
+	schema, err := ReadSchemaYAML(r)
+	if err != nil {
+		return nil, err
+	}
+	return schema.List, nil
+}
+
+// ReadSchemaYAML Read structures, enums and structs of schema file
+func ReadSchemaYAML(r io.Reader) (Schema, error) {
 	ctx := cuecontext.New()
 
 	schema, err := readStructureInfoFIleSchema(ctx)
 	if err != nil {
-		return nil, err
+		return Schema{}, err
 	}
 
 	fileData, err := readYAML(ctx, r)
 	if err != nil {
-		return nil, err
+		return Schema{}, err
 	}
 
 	fileDataValue := schema.Unify(fileData)
 	if fileDataValue.Err() != nil {
-		return nil, fmt.Errorf("ReadStructureYAML:  Invalid format")
+		return Schema{}, fmt.Errorf("ReadStructureYAML:  Invalid format")
 	}
 
 	var structureFileData structureFile
 	err = fileDataValue.Decode(&structureFileData)
 	if err != nil {
-		return nil, err
+		return Schema{}, err
 	}
 
 	if len(structureFileData.List) == 0 {
-		return nil, errors.New("ReadStructureYAML: No structure data")
+		return Schema{}, errors.New("ReadStructureYAML: No structure data")
 	}
 
 	for name, structure := range structureFileData.List {
 		if len(structure.Body) == 0 {
-			return nil, fmt.Errorf("ReadStructureYAML: Name: %s No structure body", name)
+			return Schema{}, fmt.Errorf("ReadStructureYAML: Name: %s No structure body", name)
 		}
+
+		if structure.Verbosity != "" && structure.Category == "" {
+			return Schema{}, fmt.Errorf("ReadStructureYAML: Name: %s Verbosity requires Category", name)
+		}
+		for otherName := range structureFileData.List {
+			if structure.Category == "Log"+otherName {
+				return Schema{}, fmt.Errorf("ReadStructureYAML: Name: %s Category %s conflicts with logging function of %s", name, structure.Category, otherName)
+			}
+		}
+
+		prevVersion := 1
+		for _, change := range structure.Changes {
+			if change.Version <= prevVersion || change.Version > structure.CurrentVersion() {
+				return Schema{}, fmt.Errorf("ReadStructureYAML: Name: %s Invalid change version %d", name, change.Version)
+			}
+			prevVersion = change.Version
+		}
+	}
+
+	result := Schema{Enums: structureFileData.Enums, Structs: structureFileData.Structs, List: structureFileData.List}
+	err = result.validateTypes()
+	if err != nil {
+		return Schema{}, err
 	}
 
-	return structureFileData.List, nil
+	return result, nil
 }
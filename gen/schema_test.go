@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
 	"github.com/y-akahori-ramen/ueLogHandler/gen"
 )
 
@@ -95,6 +96,52 @@ structures:
 			expectData: nil,
 			noErr:      false,
 		},
+		{
+			yaml: `
+structures:
+  list:
+    SampleStructure:
+      Version: 2
+      Body:
+        HitDamage: int32
+        Weapon: string
+      Changes:
+        - Version: 2
+          Renames:
+            HitDamage: Damage
+          Defaults:
+            Weapon: Unknown`,
+			expectData: map[string]gen.StructureInfo{
+				"SampleStructure": {
+					Version: 2,
+					Meta:    map[string]interface{}{},
+					Body: map[string]string{
+						"HitDamage": "int32",
+						"Weapon":    "string",
+					},
+					Changes: []ueloghandler.StructureChange{
+						{
+							Version:  2,
+							Renames:  map[string]string{"HitDamage": "Damage"},
+							Defaults: map[string]interface{}{"Weapon": "Unknown"},
+						},
+					},
+				},
+			},
+			noErr: true,
+		},
+		{
+			yaml: `
+structures:
+  list:
+    SampleStructure:
+      Body:
+        Damage: int32
+      Changes:
+        - Version: 2`,
+			expectData: nil,
+			noErr:      false,
+		},
 	}
 
 	for i := range testCases {
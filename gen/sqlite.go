@@ -0,0 +1,110 @@
+package gen
+
+import (
+	"fmt"
+	"sort"
+
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+func toSQLiteColumns(schema Schema, typename, fieldName string) ([]ueloghandler.SQLiteColumn, error) {
+	t, err := schema.parseType(typename)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t.Kind {
+	case fieldKindEnum, fieldKindArray:
+		// Arrays are stored as JSON text
+		return []ueloghandler.SQLiteColumn{{Name: fieldName, Type: "TEXT"}}, nil
+	case fieldKindOptional:
+		return toSQLiteColumns(schema, t.Elem.typeName(), fieldName)
+	case fieldKindStruct:
+		fields := schema.Structs[t.Name]
+		columns := []ueloghandler.SQLiteColumn{}
+		for _, name := range sortedKeys(fields) {
+			structColumns, err := toSQLiteColumns(schema, fields[name], fieldName+"_"+name)
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, structColumns...)
+		}
+		return columns, nil
+	}
+
+	switch typename {
+	case "string", "name", "text", "guid", "datetime":
+		return []ueloghandler.SQLiteColumn{{Name: fieldName, Type: "TEXT"}}, nil
+	case "vector2":
+		return []ueloghandler.SQLiteColumn{{Name: fieldName + "_X", Type: "REAL"}, {Name: fieldName + "_Y", Type: "REAL"}}, nil
+	case "vector3":
+		return []ueloghandler.SQLiteColumn{{Name: fieldName + "_X", Type: "REAL"}, {Name: fieldName + "_Y", Type: "REAL"}, {Name: fieldName + "_Z", Type: "REAL"}}, nil
+	case "rotator":
+		return []ueloghandler.SQLiteColumn{{Name: fieldName + "_Pitch", Type: "REAL"}, {Name: fieldName + "_Yaw", Type: "REAL"}, {Name: fieldName + "_Roll", Type: "REAL"}}, nil
+	case "quat":
+		return []ueloghandler.SQLiteColumn{{Name: fieldName + "_X", Type: "REAL"}, {Name: fieldName + "_Y", Type: "REAL"}, {Name: fieldName + "_Z", Type: "REAL"}, {Name: fieldName + "_W", Type: "REAL"}}, nil
+	case "linearcolor":
+		return []ueloghandler.SQLiteColumn{{Name: fieldName + "_R", Type: "REAL"}, {Name: fieldName + "_G", Type: "REAL"}, {Name: fieldName + "_B", Type: "REAL"}, {Name: fieldName + "_A", Type: "REAL"}}, nil
+	case "float":
+		fallthrough
+	case "double":
+		return []ueloghandler.SQLiteColumn{{Name: fieldName, Type: "REAL"}}, nil
+	case "int32":
+		fallthrough
+	case "uint32":
+		fallthrough
+	case "int64":
+		fallthrough
+	case "bool":
+		return []ueloghandler.SQLiteColumn{{Name: fieldName, Type: "INTEGER"}}, nil
+	case "uint64":
+		// Values exceeding int64 are stored as text
+		return []ueloghandler.SQLiteColumn{{Name: fieldName, Type: "NUMERIC"}}, nil
+	default:
+		return nil, fmt.Errorf("toSQLiteColumns:Invalid typename:%s", typename)
+	}
+}
+
+// SQLiteTables Create tables of SQLiteLogHandler from structure information
+func SQLiteTables(infoList StructureInfoList) ([]ueloghandler.SQLiteTable, error) {
+	return SQLiteSchemaTables(Schema{List: infoList})
+}
+
+// SQLiteSchemaTables Create tables of SQLiteLogHandler from schema
+//
+// Fields of structs are flattened with "_". Enums and arrays are stored as text.
+func SQLiteSchemaTables(schema Schema) ([]ueloghandler.SQLiteTable, error) {
+	infoList := schema.List
+	structureNames := []string{}
+	for structureName := range infoList {
+		structureNames = append(structureNames, structureName)
+	}
+	sort.Strings(structureNames)
+
+	tables := []ueloghandler.SQLiteTable{}
+	for _, structureName := range structureNames {
+		info := infoList[structureName]
+
+		bodyFieldNames := []string{}
+		for fieldName := range info.Body {
+			bodyFieldNames = append(bodyFieldNames, fieldName)
+		}
+		sort.Strings(bodyFieldNames)
+
+		table := ueloghandler.SQLiteTable{Type: structureName}
+		for _, fieldName := range bodyFieldNames {
+			columns, err := toSQLiteColumns(schema, info.Body[fieldName], fieldName)
+			if err != nil {
+				return nil, err
+			}
+			table.Columns = append(table.Columns, columns...)
+		}
+		// Flattened fields may clash with reserved column
+		if _, err := table.CreateStatements(); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+
+	return tables, nil
+}
@@ -0,0 +1,75 @@
+package gen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+	"github.com/y-akahori-ramen/ueLogHandler/gen"
+)
+
+func TestSQLiteTables(t *testing.T) {
+	assert := assert.New(t)
+
+	infoList, err := gen.ReadStructureInfoYAML(strings.NewReader(`
+structures:
+  list:
+    Sample:
+      Meta:
+        Tag: DataTag
+      Body:
+        Damage: int32
+        Name: string
+        Position: vector3
+        Critical: bool
+    Sample2:
+      Body:
+        Rate: float
+        Id: uint64`))
+	if !assert.NoError(err) {
+		return
+	}
+
+	tables, err := gen.SQLiteTables(infoList)
+	assert.NoError(err)
+	assert.Equal([]ueloghandler.SQLiteTable{
+		{
+			Type: "Sample",
+			Columns: []ueloghandler.SQLiteColumn{
+				{Name: "Critical", Type: "INTEGER"},
+				{Name: "Damage", Type: "INTEGER"},
+				{Name: "Name", Type: "TEXT"},
+				{Name: "Position_X", Type: "REAL"},
+				{Name: "Position_Y", Type: "REAL"},
+				{Name: "Position_Z", Type: "REAL"},
+			},
+		},
+		{
+			Type: "Sample2",
+			Columns: []ueloghandler.SQLiteColumn{
+				{Name: "Id", Type: "NUMERIC"},
+				{Name: "Rate", Type: "REAL"},
+			},
+		},
+	}, tables)
+}
+
+func TestSQLiteTablesReservedColumn(t *testing.T) {
+	schema, err := gen.ReadSchemaYAML(strings.NewReader(`
+structures:
+  structs:
+    Ref:
+      Id: int32
+  list:
+    Damage:
+      Body:
+        Log: Ref`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Flattened field Log_Id clashes with the reference to logs
+	_, err = gen.SQLiteSchemaTables(schema)
+	assert.Error(t, err)
+}
@@ -1,8 +1,47 @@
 package gen
 
+import ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+
 type StructureInfo struct {
-	Meta map[string]interface{}
-	Body map[string]string
+	// Version of structure emitted in payload header. 0 means 1.
+	Version int
+	Meta    map[string]interface{}
+	Body    map[string]string
+	// Log category of UE_LOG_<Name> macro. The macro is generated only if specified.
+	Category string
+	// Verbosity of UE_LOG_<Name> macro. Default is Log.
+	Verbosity string
+	// Changes from older versions in ascending order of version
+	Changes []ueloghandler.StructureChange
+}
+
+// CurrentVersion Get version of structure
+func (info StructureInfo) CurrentVersion() int {
+	if info.Version == 0 {
+		return 1
+	}
+	return info.Version
+}
+
+// LogVerbosity Get verbosity of UE_LOG_<Name> macro
+func (info StructureInfo) LogVerbosity() string {
+	if info.Verbosity == "" {
+		return "Log"
+	}
+	return info.Verbosity
 }
 
 type StructureInfoList map[string]StructureInfo
+
+// EnumInfo Values of enum in declaration order
+type EnumInfo []string
+
+// StructInfo Field types of struct used as type of Body fields
+type StructInfo map[string]string
+
+// Schema Structures and types defined in schema file
+type Schema struct {
+	Enums   map[string]EnumInfo
+	Structs map[string]StructInfo
+	List    StructureInfoList
+}
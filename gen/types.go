@@ -0,0 +1,285 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+)
+
+type fieldKind int
+
+const (
+	fieldKindBuiltin fieldKind = iota
+	fieldKindEnum
+	fieldKindStruct
+	fieldKindArray
+	fieldKindOptional
+)
+
+// fieldType Type of Body field
+type fieldType struct {
+	Kind fieldKind
+	// Builtin type name, enum name or struct name. Empty for array and optional.
+	Name string
+	// Element type of array and optional
+	Elem *fieldType
+}
+
+var builtinTypeNames = []string{
+	"float", "double", "int32", "uint32", "int64", "uint64", "bool", "string",
+	"vector2", "vector3", "rotator", "quat", "linearcolor", "name", "text", "guid", "datetime",
+}
+
+func isBuiltinType(typename string) bool {
+	for _, name := range builtinTypeNames {
+		if name == typename {
+			return true
+		}
+	}
+	return false
+}
+
+// parseType Resolve type name of Body field
+//
+// Element of array is builtin type or enum. Element of optional is builtin type, enum or struct.
+func (s Schema) parseType(typename string) (fieldType, error) {
+	for _, kind := range []struct {
+		prefix string
+		kind   fieldKind
+	}{{"array<", fieldKindArray}, {"optional<", fieldKindOptional}} {
+		if !strings.HasPrefix(typename, kind.prefix) || !strings.HasSuffix(typename, ">") {
+			continue
+		}
+
+		elem, err := s.parseType(typename[len(kind.prefix) : len(typename)-1])
+		if err != nil {
+			return fieldType{}, err
+		}
+		if elem.Kind == fieldKindArray || elem.Kind == fieldKindOptional || (kind.kind == fieldKindArray && elem.Kind == fieldKindStruct) {
+			return fieldType{}, fmt.Errorf("parseType:Invalid element type:%s", typename)
+		}
+		return fieldType{Kind: kind.kind, Elem: &elem}, nil
+	}
+
+	if isBuiltinType(typename) {
+		return fieldType{Kind: fieldKindBuiltin, Name: typename}, nil
+	}
+	if _, ok := s.Enums[typename]; ok {
+		return fieldType{Kind: fieldKindEnum, Name: typename}, nil
+	}
+	if _, ok := s.Structs[typename]; ok {
+		return fieldType{Kind: fieldKindStruct, Name: typename}, nil
+	}
+	return fieldType{}, fmt.Errorf("parseType:Invalid typename:%s", typename)
+}
+
+// typeName Get type name in schema file
+func (t fieldType) typeName() string {
+	switch t.Kind {
+	case fieldKindArray:
+		return "array<" + t.Elem.typeName() + ">"
+	case fieldKindOptional:
+		return "optional<" + t.Elem.typeName() + ">"
+	default:
+		return t.Name
+	}
+}
+
+// usesJSONHelper Whether the type is written with ToStructuredLogJson in C++
+func (t fieldType) usesJSONHelper() bool {
+	return t.Kind == fieldKindStruct || t.Kind == fieldKindArray || t.Kind == fieldKindOptional
+}
+
+// validateTypes Check names and references of enums and structs
+func (s Schema) validateTypes() error {
+	for name, values := range s.Enums {
+		if _, ok := s.Structs[name]; ok {
+			return fmt.Errorf("ReadStructureYAML: Name: %s Enum and struct have same name", name)
+		}
+		if _, ok := s.List[name]; ok {
+			return fmt.Errorf("ReadStructureYAML: Name: %s Enum and structure have same name", name)
+		}
+		if len(values) == 0 {
+			return fmt.Errorf("ReadStructureYAML: Name: %s No enum value", name)
+		}
+		found := map[string]bool{}
+		for _, value := range values {
+			if found[value] {
+				return fmt.Errorf("ReadStructureYAML: Name: %s Duplicated enum value %s", name, value)
+			}
+			found[value] = true
+		}
+	}
+
+	for name, fields := range s.Structs {
+		if _, ok := s.List[name]; ok {
+			return fmt.Errorf("ReadStructureYAML: Name: %s Struct and structure have same name", name)
+		}
+		if len(fields) == 0 {
+			return fmt.Errorf("ReadStructureYAML: Name: %s No struct field", name)
+		}
+		for fieldName, typename := range fields {
+			if _, err := s.parseType(typename); err != nil {
+				return fmt.Errorf("ReadStructureYAML: Name: %s Field: %s %w", name, fieldName, err)
+			}
+		}
+	}
+
+	if _, err := s.sortedStructNames(); err != nil {
+		return err
+	}
+
+	if err := s.validateGeneratedNames(); err != nil {
+		return err
+	}
+
+	for name, structure := range s.List {
+		for fieldName, typename := range structure.Body {
+			if _, err := s.parseType(typename); err != nil {
+				return fmt.Errorf("ReadStructureYAML: Name: %s Field: %s %w", name, fieldName, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// reservedCppTypeNames Commonly used Unreal Engine types which generated enums E<Name> and structs F<Name> must not redefine
+//
+// This is not a complete list of engine types. Every generated name follows the engine prefix convention,
+// so clashes with other engine or project types can not be detected here and are reported by the C++ compiler.
+var reservedCppTypeNames = map[string]bool{
+	"FVector": true, "FVector2D": true, "FVector4": true, "FRotator": true, "FQuat": true, "FTransform": true,
+	"FLinearColor": true, "FColor": true, "FString": true, "FName": true, "FText": true, "FGuid": true,
+	"FDateTime": true, "FTimespan": true, "FBox": true, "FBox2D": true, "FPlane": true, "FMatrix": true,
+	"FIntPoint": true, "FIntVector": true, "FSphere": true, "FRay": true, "FHitResult": true, "FKey": true,
+	"FSoftObjectPath": true, "FPrimaryAssetId": true, "FGameplayTag": true, "FTimerHandle": true, "FRandomStream": true,
+	"ENetRole": true, "ENetMode": true, "EAxis": true, "ECollisionChannel": true, "ECollisionEnabled": true,
+	"EMovementMode": true, "ETeleportType": true, "EEndPlayReason": true, "ETickingGroup": true, "EWorldType": true,
+	"EObjectFlags": true, "EInputEvent": true, "EAttachmentRule": true, "EDetachmentRule": true, "EPhysicalSurface": true,
+	"EMouseCursor": true, "ELogVerbosity": true,
+}
+
+const reservedCppTypeNote = " (only commonly used engine types are checked, other clashes are reported by the C++ compiler)"
+
+// validateGeneratedNames Check that generated C++ and Go types do not clash with engine types and each other, and SQLite tables with tables of SQLiteLogHandler
+func (s Schema) validateGeneratedNames() error {
+	for _, name := range sortedKeys(s.List) {
+		// SQLite identifiers are case insensitive
+		if strings.EqualFold(name, "logs") {
+			return fmt.Errorf("ReadStructureYAML: Name: %s SQLite table structured_%s clashes with structured_logs", name, name)
+		}
+		for fieldName := range s.List[name].Body {
+			if strings.EqualFold(fieldName, "log_id") {
+				return fmt.Errorf("ReadStructureYAML: Name: %s Field: %s SQLite column clashes with log_id", name, fieldName)
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(s.Enums) {
+		if reservedCppTypeNames["E"+name] {
+			return fmt.Errorf("ReadStructureYAML: Name: %s Enum E%s clashes with Unreal Engine type%s", name, name, reservedCppTypeNote)
+		}
+	}
+	for _, name := range sortedKeys(s.Structs) {
+		if reservedCppTypeNames["F"+name] {
+			return fmt.Errorf("ReadStructureYAML: Name: %s Struct F%s clashes with Unreal Engine type%s", name, name, reservedCppTypeNote)
+		}
+	}
+
+	goNames := map[string]string{}
+	addGoName := func(goName, name string) error {
+		if other, ok := goNames[goName]; ok {
+			return fmt.Errorf("ReadStructureYAML: Name: %s Generated Go name %s clashes with %s", name, goName, other)
+		}
+		goNames[goName] = name
+		return nil
+	}
+	for _, name := range sortedKeys(s.List) {
+		for _, suffix := range []string{"Meta", "Body", "Data", "HandlerFunc", "LogHandler", "Schema", "History"} {
+			if err := addGoName(name+suffix, name); err != nil {
+				return err
+			}
+		}
+		if err := addGoName("New"+name+"LogHandler", name); err != nil {
+			return err
+		}
+	}
+	for _, name := range sortedKeys(s.Structs) {
+		if err := addGoName(name, name); err != nil {
+			return err
+		}
+	}
+	for _, name := range sortedKeys(s.Enums) {
+		if err := addGoName(name, name); err != nil {
+			return err
+		}
+		for _, value := range s.Enums[name] {
+			if err := addGoName(name+value, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sortedStructNames Get struct names in order of dependency. Structs used by a struct come first.
+func (s Schema) sortedStructNames() ([]string, error) {
+	names := []string{}
+	state := map[string]int{}
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("ReadStructureYAML: Name: %s Struct refers itself", name)
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+		fields := s.Structs[name]
+		for _, fieldName := range sortedKeys(fields) {
+			t, err := s.parseType(fields[fieldName])
+			if err != nil {
+				return err
+			}
+			if t.Kind == fieldKindOptional {
+				t = *t.Elem
+			}
+			if t.Kind == fieldKindStruct {
+				if err := visit(t.Name); err != nil {
+					return err
+				}
+			}
+		}
+		state[name] = visited
+		names = append(names, name)
+		return nil
+	}
+
+	for _, name := range sortedKeys(s.Structs) {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+// usesJSONHelper Whether any field is written with ToStructuredLogJson in C++
+func (s Schema) usesJSONHelper() bool {
+	if len(s.Structs) != 0 {
+		return true
+	}
+	for _, structure := range s.List {
+		for _, typename := range structure.Body {
+			if t, err := s.parseType(typename); err == nil && t.usesJSONHelper() {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+package gen_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+	"github.com/y-akahori-ramen/ueLogHandler/gen"
+)
+
+const richTypesYAML = `
+structures:
+  enums:
+    Weapon: [Sword, Bow]
+  structs:
+    HitInfo:
+      Bone: name
+      Normal: vector3
+    Hit:
+      Info: HitInfo
+      Weapon: optional<Weapon>
+  list:
+    Damage:
+      Body:
+        Amount: int32
+        Weapon: Weapon
+        Hit: Hit
+        Tags: array<name>
+        Critical: optional<bool>
+        Rotation: rotator
+        At: datetime`
+
+func TestSchemaTypes(t *testing.T) {
+	assert := assert.New(t)
+
+	schema, err := gen.ReadSchemaYAML(strings.NewReader(richTypesYAML))
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal(map[string]gen.EnumInfo{"Weapon": {"Sword", "Bow"}}, schema.Enums)
+	assert.Equal(map[string]gen.StructInfo{
+		"HitInfo": {"Bone": "name", "Normal": "vector3"},
+		"Hit":     {"Info": "HitInfo", "Weapon": "optional<Weapon>"},
+	}, schema.Structs)
+
+	invalidBodies := []string{
+		// Unknown type
+		`Value: Unknown`,
+		// Array of struct
+		`Value: array<HitInfo>`,
+		// Nested array
+		`Value: array<array<int32>>`,
+		// Optional array
+		`Value: optional<array<int32>>`,
+	}
+	for _, body := range invalidBodies {
+		_, err := gen.ReadSchemaYAML(strings.NewReader(fmt.Sprintf(`
+structures:
+  structs:
+    HitInfo:
+      Bone: name
+  list:
+    Damage:
+      Body:
+        %s`, body)))
+		assert.Error(err, body)
+	}
+
+	_, err = gen.ReadSchemaYAML(strings.NewReader(`
+structures:
+  structs:
+    A:
+      B: B
+    B:
+      A: optional<A>
+  list:
+    Damage:
+      Body:
+        Value: A`))
+	assert.Error(err, "recursive struct")
+
+	clashingSchemas := map[string]string{
+		"engine struct": `
+structures:
+  structs:
+    Vector:
+      Length: float
+  list:
+    Damage:
+      Body:
+        Value: Vector`,
+		"engine enum": `
+structures:
+  enums:
+    NetRole: [Simulated, Authority]
+  list:
+    Damage:
+      Body:
+        Value: NetRole`,
+		"generated Go type": `
+structures:
+  structs:
+    DamageBody:
+      Length: float
+  list:
+    Damage:
+      Body:
+        Value: DamageBody`,
+		"enum constant": `
+structures:
+  enums:
+    Weapon: [Sword]
+  structs:
+    WeaponSword:
+      Length: float
+  list:
+    Damage:
+      Body:
+        Value: Weapon
+        Sword: WeaponSword`,
+		"SQLite table": `
+structures:
+  list:
+    Logs:
+      Body:
+        Value: int32`,
+		"SQLite column": `
+structures:
+  list:
+    Damage:
+      Body:
+        Log_id: int32`,
+	}
+	for name, yaml := range clashingSchemas {
+		_, err := gen.ReadSchemaYAML(strings.NewReader(yaml))
+		if assert.Error(err, name) {
+			assert.NotContains(err.Error(), "Invalid format", name)
+			if strings.HasPrefix(name, "engine") {
+				assert.Contains(err.Error(), "only commonly used engine types are checked", name)
+			}
+		}
+	}
+}
+
+func TestGenSchemaFile(t *testing.T) {
+	assert := assert.New(t)
+
+	schema, err := gen.ReadSchemaYAML(strings.NewReader(richTypesYAML))
+	if !assert.NoError(err) {
+		return
+	}
+
+	var cpp bytes.Buffer
+	assert.Error(gen.GenCppSchemaFile(&cpp, gen.CppConfig{}, schema), "HeaderName is required for UENUM")
+
+	cpp.Reset()
+	if !assert.NoError(gen.GenCppSchemaFile(&cpp, gen.CppConfig{Namespace: "structuredLog", HeaderName: "sample.h"}, schema)) {
+		return
+	}
+	cppStr := cpp.String()
+	assert.Contains(cppStr, `#include "sample.generated.h"`)
+	assert.Contains(cppStr, "UENUM(BlueprintType)\nenum class EWeapon : uint8\n{\n\tSword,\n\tBow,\n};")
+	// Structs used by other structs are declared first
+	assert.Less(strings.Index(cppStr, "struct FHitInfo"), strings.Index(cppStr, "struct FHit\n"))
+	assert.Contains(cppStr, "FString LogDamage(int32 Amount,const FDateTime& At,const TOptional<bool>& Critical,const FHit& Hit,const FRotator& Rotation,const TArray<FName>& Tags,EWeapon Weapon)")
+	assert.Contains(cppStr, `"Hit":%s,"Rotation":{"Pitch":%s,"Yaw":%s,"Roll":%s},"Tags":%s,"Weapon":"%s"`)
+
+	var goCode bytes.Buffer
+	if !assert.NoError(gen.GenGoSchemaFile(&goCode, "sample", schema)) {
+		return
+	}
+	goStr := goCode.String()
+	assert.Contains(goStr, "type Weapon string")
+	assert.Contains(goStr, `WeaponSword Weapon = "Sword"`)
+	assert.Contains(goStr, "Weapon *Weapon")
+	assert.Contains(goStr, "Tags     []string")
+	assert.Contains(goStr, "At       time.Time")
+
+	tables, err := gen.SQLiteSchemaTables(schema)
+	assert.NoError(err)
+	assert.Equal([]ueloghandler.SQLiteTable{
+		{
+			Type: "Damage",
+			Columns: []ueloghandler.SQLiteColumn{
+				{Name: "Amount", Type: "INTEGER"},
+				{Name: "At", Type: "TEXT"},
+				{Name: "Critical", Type: "INTEGER"},
+				{Name: "Hit_Info_Bone", Type: "TEXT"},
+				{Name: "Hit_Info_Normal_X", Type: "REAL"},
+				{Name: "Hit_Info_Normal_Y", Type: "REAL"},
+				{Name: "Hit_Info_Normal_Z", Type: "REAL"},
+				{Name: "Hit_Weapon", Type: "TEXT"},
+				{Name: "Rotation_Pitch", Type: "REAL"},
+				{Name: "Rotation_Yaw", Type: "REAL"},
+				{Name: "Rotation_Roll", Type: "REAL"},
+				{Name: "Tags", Type: "TEXT"},
+				{Name: "Weapon", Type: "TEXT"},
+			},
+		},
+	}, tables)
+}
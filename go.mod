@@ -5,6 +5,8 @@ go 1.18
 require (
 	cuelang.org/go v0.4.3
 	github.com/dave/jennifer v1.5.0
+	github.com/klauspost/compress v1.15.15
+	github.com/mattn/go-sqlite3 v1.14.16
 	github.com/stretchr/testify v1.7.1
 )
 
@@ -15,3 +15,11 @@ type funcLogHanlder struct {
 func (l *funcLogHanlder) HandleLog(log Log) error {
 	return l.function(log)
 }
+
+// logSource Get source of log. defaultSource is used if log has no source.
+func logSource(log Log, defaultSource string) string {
+	if log.Source != "" {
+		return log.Source
+	}
+	return defaultSource
+}
@@ -0,0 +1,316 @@
+package ueloghandler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RetryConfig Retry settings for handlers sending logs over HTTP
+//
+// Requests are retried on network errors, 429 Too Many Requests and 5xx responses.
+// The wait time doubles on each retry starting from InitialBackoff up to MaxBackoff.
+type RetryConfig struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+var DefaultRetryConfig = RetryConfig{MaxRetries: 3, InitialBackoff: time.Millisecond * 500, MaxBackoff: time.Second * 10}
+
+type httpSender struct {
+	client  *http.Client
+	headers map[string]string
+	retry   RetryConfig
+}
+
+func newHTTPSender(client *http.Client, headers map[string]string, retry *RetryConfig) *httpSender {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if retry == nil {
+		retry = &DefaultRetryConfig
+	}
+	return &httpSender{client: client, headers: headers, retry: *retry}
+}
+
+// temporaryError Error of request which may succeed later. e.g. 503 Service Unavailable after all retries
+type temporaryError struct {
+	err error
+}
+
+func (e *temporaryError) Error() string {
+	return e.err.Error()
+}
+
+func (e *temporaryError) Unwrap() error {
+	return e.err
+}
+
+// post Send body and return response body
+//
+// Errors which may succeed later are returned as *temporaryError.
+func (s *httpSender) post(ctx context.Context, url, contentType string, body []byte) ([]byte, error) {
+	backoff := s.retry.InitialBackoff
+	for retry := 0; ; retry++ {
+		resBody, retryable, err := s.postOnce(ctx, url, contentType, body)
+		if err == nil || !retryable {
+			return resBody, err
+		}
+		if retry >= s.retry.MaxRetries {
+			return resBody, &temporaryError{err: err}
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, &temporaryError{err: ctx.Err()}
+		}
+
+		backoff *= 2
+		if s.retry.MaxBackoff > 0 && backoff > s.retry.MaxBackoff {
+			backoff = s.retry.MaxBackoff
+		}
+	}
+}
+
+func (s *httpSender) postOnce(ctx context.Context, url, contentType string, body []byte) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for key, value := range s.headers {
+		req.Header.Set(key, value)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, ctx.Err() == nil, err
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, true, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		retryable := res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+		return resBody, retryable, fmt.Errorf("post %s failed: %s %s", url, res.Status, bytes.TrimSpace(resBody))
+	}
+
+	return resBody, false, nil
+}
+
+// defaultMaxBufferedBatches Number of batches kept for retry by default
+const defaultMaxBufferedBatches = 10
+
+// joinErrors Join two errors, either of which may be nil
+//
+// The second error is wrapped so that *temporaryError can be found with errors.As.
+func joinErrors(first, second error) error {
+	if first == nil {
+		return second
+	}
+	if second == nil {
+		return first
+	}
+	return fmt.Errorf("%v; %w", first, second)
+}
+
+// sendBuffer Buffer of items sent in batches
+//
+// Items which failed with *temporaryError are put back to be sent on the next flush.
+// At most limit items are kept and the oldest items exceeding it are dropped.
+type sendBuffer[T any] struct {
+	mu        sync.Mutex
+	items     []T
+	batchSize int
+	limit     int
+	// Number of items dropped since the last flush
+	dropped int
+}
+
+func newSendBuffer[T any](batchSize, limit int) *sendBuffer[T] {
+	if limit <= 0 {
+		limit = batchSize * defaultMaxBufferedBatches
+	}
+	if limit < batchSize {
+		limit = batchSize
+	}
+	return &sendBuffer[T]{batchSize: batchSize, limit: limit}
+}
+
+// add Add item and return true if a batch is ready
+func (b *sendBuffer[T]) add(item T) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.items = append(b.items, item)
+	b.trim()
+	return len(b.items) >= b.batchSize
+}
+
+// sendAll Adapt function which sends or fails a batch as a whole to flush
+//
+// The whole batch is sent again if it failed with *temporaryError.
+func sendAll[T any](send func(batch []T) error) func(batch []T) ([]T, error) {
+	return func(batch []T) ([]T, error) {
+		err := send(batch)
+		var tempErr *temporaryError
+		if errors.As(err, &tempErr) {
+			return batch, err
+		}
+		return nil, err
+	}
+}
+
+// flush Send all items in batches
+//
+// send returns the items of batch to be sent again on the next flush with *temporaryError.
+// Flush stops at the first such batch. Items rejected with other errors are dropped and the first error is returned after sending the others.
+func (b *sendBuffer[T]) flush(send func(batch []T) ([]T, error)) error {
+	var firstErr error
+	for {
+		b.mu.Lock()
+		n := len(b.items)
+		if n > b.batchSize {
+			n = b.batchSize
+		}
+		batch := b.items[:n:n]
+		b.items = b.items[n:]
+		b.mu.Unlock()
+
+		if len(batch) == 0 {
+			return b.withDropped(firstErr)
+		}
+
+		retry, err := send(batch)
+		if len(retry) != 0 {
+			b.requeue(retry)
+			return b.withDropped(joinErrors(firstErr, err))
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+}
+
+// requeue Put items back in front of buffer
+func (b *sendBuffer[T]) requeue(retry []T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	items := make([]T, 0, len(retry)+len(b.items))
+	items = append(items, retry...)
+	items = append(items, b.items...)
+	b.items = items
+	b.trim()
+}
+
+// trim Drop the oldest items exceeding limit. Must be called with mu locked.
+func (b *sendBuffer[T]) trim() {
+	if len(b.items) > b.limit {
+		dropped := len(b.items) - b.limit
+		b.items = b.items[dropped:]
+		b.dropped += dropped
+	}
+}
+
+// withDropped Add the number of items dropped since the last flush to err
+func (b *sendBuffer[T]) withDropped(err error) error {
+	b.mu.Lock()
+	dropped := b.dropped
+	b.dropped = 0
+	b.mu.Unlock()
+
+	if dropped == 0 {
+		return err
+	}
+	if err == nil {
+		return fmt.Errorf("dropped %d oldest buffered items", dropped)
+	}
+	return fmt.Errorf("%w: dropped %d oldest buffered items", err, dropped)
+}
+
+// backgroundFlusher Run flush in a goroutine so that HandleLog does not wait for sending
+//
+// Errors are passed to onError. If onError is nil, they are kept and returned by wait.
+type backgroundFlusher struct {
+	flush   func(ctx context.Context) error
+	onError func(err error)
+
+	mu sync.Mutex
+	// Whether flush is requested after the running one started
+	pending bool
+	// Closed when the running goroutine ends. nil if not running.
+	done chan struct{}
+	err  error
+}
+
+// trigger Start flush in background. If flush is running, it runs once more after that.
+func (f *backgroundFlusher) trigger() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.pending = true
+	if f.done == nil {
+		f.done = make(chan struct{})
+		go f.run(f.done)
+	}
+}
+
+func (f *backgroundFlusher) run(done chan struct{}) {
+	defer close(done)
+	for {
+		f.mu.Lock()
+		if !f.pending {
+			f.done = nil
+			f.mu.Unlock()
+			return
+		}
+		f.pending = false
+		f.mu.Unlock()
+
+		if err := f.flush(context.Background()); err != nil {
+			f.report(err)
+		}
+	}
+}
+
+func (f *backgroundFlusher) report(err error) {
+	if f.onError != nil {
+		f.onError(err)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = joinErrors(f.err, err)
+}
+
+// wait Wait for background flush and return errors kept since the last call
+func (f *backgroundFlusher) wait(ctx context.Context) error {
+	f.mu.Lock()
+	done := f.done
+	f.mu.Unlock()
+
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	err := f.err
+	f.err = nil
+	return err
+}
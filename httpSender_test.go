@@ -0,0 +1,82 @@
+package ueloghandler_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// failingServer Test server responding with status to the first failures requests and 200 OK to the others
+type failingServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	failures int
+	status   int
+	requests int
+	// Body of 200 OK response
+	response string
+	// Bodies of succeeded requests
+	bodies []string
+}
+
+func newFailingServer(failures, status int) *failingServer {
+	s := &failingServer{failures: failures, status: status}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests++
+		if s.failures > 0 {
+			s.failures--
+			w.WriteHeader(s.status)
+			return
+		}
+		s.bodies = append(s.bodies, string(body))
+		io.WriteString(w, s.response)
+	}))
+	return s
+}
+
+func (s *failingServer) setFailures(failures int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = failures
+}
+
+func (s *failingServer) requestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *failingServer) succeededBodies() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.bodies...)
+}
+
+// errorRecorder Record errors passed to OnError callback
+type errorRecorder struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (r *errorRecorder) onError(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, err)
+}
+
+func (r *errorRecorder) messages() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	messages := []string{}
+	for _, err := range r.errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
@@ -2,6 +2,7 @@ package ueloghandler
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
 	"strings"
 	"time"
@@ -15,6 +16,8 @@ type Log struct {
 	Verbosity string
 	Time      string
 	Frame     string
+	// Where the log was read from. e.g. path to log file. Empty if unknown.
+	Source string
 }
 
 func (l *Log) ParseTime(loc *time.Location) (time.Time, error) {
@@ -71,6 +74,26 @@ func NewLog(logStr string) Log {
 	return logInfo
 }
 
+// Message Get log text without time, frame, category and verbosity
+//
+// Example:
+//
+//	log: [2022.05.01-17.56.38:615][429]LogTemp: Warning: WarningLog
+//	message: WarningLog
+func (l *Log) Message() string {
+	message := l.Log
+	if l.Time != "" {
+		message = strings.TrimPrefix(message, fmt.Sprintf("[%s][%s]", l.Time, l.Frame))
+	}
+	if l.Category != "" {
+		message = strings.TrimPrefix(message, l.Category+": ")
+	}
+	if l.Verbosity != "" {
+		message = strings.TrimPrefix(message, l.Verbosity+": ")
+	}
+	return strings.TrimRight(message, "\r\n")
+}
+
 var convertUTF8_LFReplacer = strings.NewReplacer(
 	"\r\n", "\n",
 	"\ufeff", "",
@@ -154,3 +154,23 @@ func TestParseTimeg(t *testing.T) {
 	actualTime, err = log.ParseTime(time.UTC)
 	assert.Equal(err, ueloghandler.ErrNoTimeData)
 }
+
+func TestMessage(t *testing.T) {
+	type testCase struct {
+		str  string
+		want string
+	}
+	testCases := []testCase{
+		{str: "[2022.05.01-17.56.38:615][429]LogTemp: Warning: WarningLog\n", want: "WarningLog"},
+		{str: "[2022.05.01-17.56.38:615][  9]LogTemp: Line1\nLine2\n", want: "Line1\nLine2"},
+		{str: "LogWindows: Failed to load 'aqProf.dll' (GetLastError=126)", want: "Failed to load 'aqProf.dll' (GetLastError=126)"},
+		{str: "Log file open, 05/02/22 13:01:53", want: "Log file open, 05/02/22 13:01:53"},
+		{str: "[2022.05.22-01.11.05:634][733]Command not recognized: invalid command", want: "Command not recognized: invalid command"},
+		{str: "[2022.05.02-14.02.49:793][ 29]UATHelper: Packaging (Windows): LogShaderCompilers: Display:", want: "Packaging (Windows): LogShaderCompilers: Display:"},
+	}
+
+	for _, testCase := range testCases {
+		log := ueloghandler.NewLog(testCase.str)
+		assert.Equal(t, testCase.want, log.Message(), testCase.str)
+	}
+}
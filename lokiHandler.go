@@ -0,0 +1,199 @@
+package ueloghandler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultShipBatchSize = 1000
+
+type LokiConfig struct {
+	// URL of Loki push API. e.g. http://localhost:3100/loki/api/v1/push
+	Endpoint string
+	// Additional HTTP headers. e.g. X-Scope-OrgID
+	Headers map[string]string
+	// Labels added to all streams. e.g. {"job": "editor"}
+	Labels map[string]string
+	// Value of source label used when Log.Source is empty. e.g. path to log file
+	Source string
+	// Number of logs sent in one request. Default is 1000.
+	BatchSize int
+	// Maximum number of logs kept for retry when push fails. The oldest logs exceeding it are dropped. Default is 10 times BatchSize.
+	MaxBuffered int
+	// Location of log time. Default is UTC.
+	Location *time.Location
+	// HTTP client used for push. Default is http.DefaultClient.
+	Client *http.Client
+	// Retry settings. Default is DefaultRetryConfig.
+	Retry *RetryConfig
+	// Called with errors of pushes in background. If nil, the errors are returned by the next Flush.
+	OnError func(err error)
+}
+
+// LokiLogHandler Push logs to Grafana Loki
+//
+// Each log is labeled with category, verbosity and source.
+// Logs are buffered and pushed in background when the buffer reaches BatchSize, so HandleLog does not wait for Loki.
+// Logs of a failed push are kept and pushed again on the next push unless Loki rejected them.
+// While Loki is slow or down, the oldest logs exceeding MaxBuffered are dropped.
+// Call Flush to push the remaining logs after watching ends.
+type LokiLogHandler struct {
+	config  LokiConfig
+	sender  *httpSender
+	logs    *sendBuffer[Log]
+	flusher *backgroundFlusher
+
+	// Time of the last pushed log with time. Used for logs without time.
+	mu       sync.Mutex
+	lastTime time.Time
+}
+
+func NewLokiLogHandler(config LokiConfig) *LokiLogHandler {
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultShipBatchSize
+	}
+	if config.Location == nil {
+		config.Location = time.UTC
+	}
+	h := &LokiLogHandler{
+		config: config,
+		sender: newHTTPSender(config.Client, config.Headers, config.Retry),
+		logs:   newSendBuffer[Log](config.BatchSize, config.MaxBuffered),
+	}
+	h.flusher = &backgroundFlusher{flush: h.push, onError: config.OnError}
+	return h
+}
+
+func (h *LokiLogHandler) HandleLog(log Log) error {
+	if h.logs.add(log) {
+		h.flusher.trigger()
+	}
+	return nil
+}
+
+// Flush Wait for push in background and push buffered logs
+//
+// Errors of pushes in background are returned together if OnError is nil.
+func (h *LokiLogHandler) Flush(ctx context.Context) error {
+	err := h.flusher.wait(ctx)
+	return joinErrors(err, h.push(ctx))
+}
+
+func (h *LokiLogHandler) push(ctx context.Context) error {
+	return h.logs.flush(sendAll(func(logs []Log) error {
+		h.mu.Lock()
+		lastTime := h.lastTime
+		h.mu.Unlock()
+
+		req, lastLogTime := h.newRequest(logs, lastTime)
+		body, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+
+		_, err = h.sender.post(ctx, h.config.Endpoint, "application/json", body)
+		if err == nil && !lastLogTime.IsZero() {
+			h.mu.Lock()
+			h.lastTime = lastLogTime
+			h.mu.Unlock()
+		}
+		return err
+	}))
+}
+
+func (h *LokiLogHandler) labels(log Log) map[string]string {
+	labels := map[string]string{}
+	for key, value := range h.config.Labels {
+		labels[key] = value
+	}
+
+	if log.Category != "" {
+		labels["category"] = log.Category
+	}
+	if log.Verbosity != "" {
+		labels["verbosity"] = log.Verbosity
+	} else {
+		labels["verbosity"] = "Log"
+	}
+	if source := logSource(log, h.config.Source); source != "" {
+		labels["source"] = source
+	}
+
+	return labels
+}
+
+// newRequest Create request of logs
+//
+// Logs without time use the time of the previous log to keep the order in a stream.
+// Logs before the first log with time use lastTime of the previous request, or the time of the first log with time.
+// Returns the time of the last log with time in logs, or zero if no log has time.
+func (h *LokiLogHandler) newRequest(logs []Log, lastTime time.Time) (lokiPushRequest, time.Time) {
+	streams := map[string]*lokiStream{}
+	keys := []string{}
+
+	var lastLogTime time.Time
+	if lastTime.IsZero() {
+		// Current time is used only if no log has time
+		lastTime = time.Now()
+		for _, log := range logs {
+			if t, err := log.ParseTime(h.config.Location); err == nil {
+				lastTime = t
+				break
+			}
+		}
+	}
+	for _, log := range logs {
+		if t, err := log.ParseTime(h.config.Location); err == nil {
+			lastTime = t
+			lastLogTime = t
+		}
+
+		labels := h.labels(log)
+		key := lokiStreamKey(labels)
+		stream, ok := streams[key]
+		if !ok {
+			stream = &lokiStream{Stream: labels}
+			streams[key] = stream
+			keys = append(keys, key)
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(lastTime.UnixNano(), 10), strings.TrimRight(log.Log, "\n")})
+	}
+
+	req := lokiPushRequest{}
+	for _, key := range keys {
+		req.Streams = append(req.Streams, *streams[key])
+	}
+	return req, lastLogTime
+}
+
+func lokiStreamKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, key := range keys {
+		sb.WriteString(strconv.Quote(key))
+		sb.WriteString("=")
+		sb.WriteString(strconv.Quote(labels[key]))
+		sb.WriteString(",")
+	}
+	return sb.String()
+}
+
+type lokiPushRequest struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
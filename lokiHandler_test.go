@@ -0,0 +1,161 @@
+package ueloghandler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+type lokiTestRequest struct {
+	Streams []struct {
+		Stream map[string]string
+		Values [][2]string
+	}
+}
+
+func TestLokiLogHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	requests := []lokiTestRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("tenant", r.Header.Get("X-Scope-OrgID"))
+
+		var req lokiTestRequest
+		assert.NoError(json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	handler := ueloghandler.NewLokiLogHandler(ueloghandler.LokiConfig{
+		Endpoint: server.URL,
+		Headers:  map[string]string{"X-Scope-OrgID": "tenant"},
+		Labels:   map[string]string{"job": "editor"},
+		Source:   "ue.log",
+	})
+
+	logs := []string{
+		"[2022.05.01-17.56.38:615][429]LogTemp: Warning: Warning1\n",
+		"[2022.05.01-17.56.38:616][429]LogHttp: Request\n",
+		"[2022.05.01-17.56.38:617][430]LogTemp: Warning: Warning2\nLine2\n",
+	}
+	for _, log := range logs {
+		assert.NoError(handler.HandleLog(ueloghandler.NewLog(log)))
+	}
+	assert.Len(requests, 0)
+	assert.NoError(handler.Flush(context.Background()))
+
+	if !assert.Len(requests, 1) || !assert.Len(requests[0].Streams, 2) {
+		return
+	}
+
+	warningStream := requests[0].Streams[0]
+	assert.Equal(map[string]string{"job": "editor", "category": "LogTemp", "verbosity": "Warning", "source": "ue.log"}, warningStream.Stream)
+	wantTime := time.Date(2022, 5, 1, 17, 56, 38, (int)(615*time.Millisecond), time.UTC)
+	assert.Equal([][2]string{
+		{strconv.FormatInt(wantTime.UnixNano(), 10), "[2022.05.01-17.56.38:615][429]LogTemp: Warning: Warning1"},
+		{strconv.FormatInt(wantTime.Add(time.Millisecond*2).UnixNano(), 10), "[2022.05.01-17.56.38:617][430]LogTemp: Warning: Warning2\nLine2"},
+	}, warningStream.Values)
+
+	logStream := requests[0].Streams[1]
+	assert.Equal(map[string]string{"job": "editor", "category": "LogHttp", "verbosity": "Log", "source": "ue.log"}, logStream.Stream)
+	assert.Len(logStream.Values, 1)
+}
+
+func TestLokiLogHandlerRetry(t *testing.T) {
+	assert := assert.New(t)
+
+	server := newFailingServer(2, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	retry := ueloghandler.RetryConfig{MaxRetries: 2, InitialBackoff: time.Millisecond}
+	handler := ueloghandler.NewLokiLogHandler(ueloghandler.LokiConfig{Endpoint: server.URL, BatchSize: 1, Retry: &retry})
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("LogTemp: test")))
+	assert.NoError(handler.Flush(context.Background()))
+	assert.Equal(3, server.requestCount())
+	assert.Len(server.succeededBodies(), 1)
+}
+
+func TestLokiLogHandlerRequeue(t *testing.T) {
+	assert := assert.New(t)
+
+	server := newFailingServer(3, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	var errs errorRecorder
+	retry := ueloghandler.RetryConfig{MaxRetries: 2, InitialBackoff: time.Millisecond}
+	handler := ueloghandler.NewLokiLogHandler(ueloghandler.LokiConfig{Endpoint: server.URL, BatchSize: 1, Retry: &retry, OnError: errs.onError})
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("LogTemp: test1")))
+	// The failed log is pushed again by Flush
+	assert.NoError(handler.Flush(context.Background()))
+	assert.Equal(4, server.requestCount())
+	assert.Contains(errs.messages(), "503 Service Unavailable")
+
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("LogTemp: test2")))
+	assert.NoError(handler.Flush(context.Background()))
+	bodies := server.succeededBodies()
+	if assert.Len(bodies, 2) {
+		assert.Contains(bodies[0], "test1")
+		assert.Contains(bodies[1], "test2")
+	}
+}
+
+func TestLokiLogHandlerRejected(t *testing.T) {
+	assert := assert.New(t)
+
+	server := newFailingServer(1, http.StatusBadRequest)
+	defer server.Close()
+
+	handler := ueloghandler.NewLokiLogHandler(ueloghandler.LokiConfig{Endpoint: server.URL, BatchSize: 1})
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("LogTemp: test1")))
+	assert.Error(handler.Flush(context.Background()))
+
+	// Logs rejected by Loki are not pushed again
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("LogTemp: test2")))
+	assert.NoError(handler.Flush(context.Background()))
+	bodies := server.succeededBodies()
+	if assert.Len(bodies, 1) {
+		assert.Contains(bodies[0], "test2")
+	}
+}
+
+func TestLokiLogHandlerUntimedLogs(t *testing.T) {
+	assert := assert.New(t)
+
+	server := newFailingServer(0, http.StatusOK)
+	defer server.Close()
+
+	handler := ueloghandler.NewLokiLogHandler(ueloghandler.LokiConfig{Endpoint: server.URL, BatchSize: 2})
+	logs := []string{
+		"Log file open, 05/02/22 02:56:31\n",
+		"[2022.05.01-17.56.38:615][429]LogTemp: Timed\n",
+		// Pushed in the next request
+		"Untimed\n",
+	}
+	for _, log := range logs {
+		assert.NoError(handler.HandleLog(ueloghandler.NewLog(log)))
+	}
+	assert.NoError(handler.Flush(context.Background()))
+
+	bodies := server.succeededBodies()
+	if !assert.Len(bodies, 2) {
+		return
+	}
+	wantTime := strconv.FormatInt(time.Date(2022, 5, 1, 17, 56, 38, (int)(615*time.Millisecond), time.UTC).UnixNano(), 10)
+	for _, body := range bodies {
+		var req lokiTestRequest
+		assert.NoError(json.Unmarshal([]byte(body), &req))
+		for _, stream := range req.Streams {
+			for _, value := range stream.Values {
+				assert.Equal(wantTime, value[0], value[1])
+			}
+		}
+	}
+}
@@ -0,0 +1,350 @@
+package ueloghandler
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var metricNamePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+var DefaultHistogramBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// MetricsLogHandler Maintain Prometheus metrics derived from logs
+//
+// The following metrics are maintained.
+//
+//	<namespace>_logs_total{category, verbosity}: Number of logs
+//	<namespace>_structured_logs_total{type}: Number of structured log payloads per Meta.Type
+//	<namespace>_structured_log_errors_total: Number of structured log payloads which could not be parsed
+//	<namespace>_log_templates_total{template_id}: Number of logs per template ID. Only maintained after EnableTemplateCounts is called.
+//
+// In addition, gauges and histograms whose value is extracted from logs by regular expression can be added.
+//
+// MetricsLogHandler implements http.Handler and serves metrics in Prometheus text format.
+type MetricsLogHandler struct {
+	namespace        string
+	mu               sync.Mutex
+	logCounts        map[metricsLogKey]uint64
+	structuredCounts map[string]uint64
+	structuredErrors uint64
+	templateCounts   map[string]uint64
+	fingerprinter    Fingerprinter
+	templateLimit    int
+	extractors       []*metricsExtractor
+}
+
+type metricsLogKey struct {
+	category, verbosity string
+}
+
+type metricsExtractor struct {
+	name, help string
+	pattern    *regexp.Regexp
+	histogram  bool
+
+	// gauge
+	value float64
+	set   bool
+
+	// histogram
+	buckets      []float64
+	bucketCounts []uint64
+	count        uint64
+	sum          float64
+}
+
+// NewMetricsLogHandler Create handler whose metric names start with namespace. Default namespace is ue.
+func NewMetricsLogHandler(namespace string) (*MetricsLogHandler, error) {
+	if namespace == "" {
+		namespace = "ue"
+	}
+	if !metricNamePattern.MatchString(namespace) {
+		return nil, fmt.Errorf("invalid metric namespace: %s", namespace)
+	}
+	return &MetricsLogHandler{
+		namespace:        namespace,
+		logCounts:        make(map[metricsLogKey]uint64),
+		structuredCounts: make(map[string]uint64),
+		templateCounts:   make(map[string]uint64),
+	}, nil
+}
+
+// MetricsOtherTemplateID template_id label of logs whose template IDs exceed the limit of EnableTemplateCounts
+const MetricsOtherTemplateID = "other"
+
+// EnableTemplateCounts Count logs per template ID given by fingerprinter for up to limit template IDs
+//
+// Template IDs are unbounded, so they are not counted by default to keep the number of series bounded.
+// Logs of new template IDs seen after limit template IDs are counted as MetricsOtherTemplateID.
+func (h *MetricsLogHandler) EnableTemplateCounts(fingerprinter Fingerprinter, limit int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.fingerprinter = fingerprinter
+	h.templateLimit = limit
+}
+
+// AddGauge Add gauge set to the value captured by the first submatch of pattern
+//
+// Example:
+//
+//	handler.AddGauge("streaming_hitch_ms", "Last streaming hitch", regexp.MustCompile(`LogStreaming: .*hitch (\d+)ms`))
+func (h *MetricsLogHandler) AddGauge(name, help string, pattern *regexp.Regexp) error {
+	return h.addExtractor(&metricsExtractor{name: name, help: help, pattern: pattern})
+}
+
+// AddHistogram Add histogram observing the value captured by the first submatch of pattern
+//
+// DefaultHistogramBuckets is used if buckets is nil.
+// +Inf bucket is always written, so +Inf and duplicated bounds in buckets are ignored.
+func (h *MetricsLogHandler) AddHistogram(name, help string, pattern *regexp.Regexp, buckets []float64) error {
+	if buckets == nil {
+		buckets = DefaultHistogramBuckets
+	}
+	sortedBuckets := []float64{}
+	for _, bound := range buckets {
+		if !math.IsInf(bound, 1) && !math.IsNaN(bound) {
+			sortedBuckets = append(sortedBuckets, bound)
+		}
+	}
+	sort.Float64s(sortedBuckets)
+	for i := len(sortedBuckets) - 1; i > 0; i-- {
+		if sortedBuckets[i] == sortedBuckets[i-1] {
+			sortedBuckets = append(sortedBuckets[:i], sortedBuckets[i+1:]...)
+		}
+	}
+
+	return h.addExtractor(&metricsExtractor{
+		name:         name,
+		help:         help,
+		pattern:      pattern,
+		histogram:    true,
+		buckets:      sortedBuckets,
+		bucketCounts: make([]uint64, len(sortedBuckets)),
+	})
+}
+
+func (h *MetricsLogHandler) addExtractor(extractor *metricsExtractor) error {
+	if !metricNamePattern.MatchString(extractor.name) {
+		return fmt.Errorf("invalid metric name: %s", extractor.name)
+	}
+	if extractor.pattern.NumSubexp() < 1 {
+		return fmt.Errorf("pattern has no submatch: %s", extractor.pattern)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	names := map[string]bool{}
+	for _, name := range h.builtinNames() {
+		names[name] = true
+	}
+	for _, e := range h.extractors {
+		for _, name := range e.seriesNames() {
+			names[name] = true
+		}
+	}
+	for _, name := range extractor.seriesNames() {
+		if names[name] {
+			return fmt.Errorf("metric already exists: %s", name)
+		}
+	}
+	h.extractors = append(h.extractors, extractor)
+	return nil
+}
+
+// builtinNames Names of metrics maintained by MetricsLogHandler
+func (h *MetricsLogHandler) builtinNames() []string {
+	return []string{
+		h.namespace + "_logs_total",
+		h.namespace + "_structured_logs_total",
+		h.namespace + "_structured_log_errors_total",
+		h.namespace + "_log_templates_total",
+	}
+}
+
+// seriesNames Names of samples written for the extractor
+func (e *metricsExtractor) seriesNames() []string {
+	if !e.histogram {
+		return []string{e.name}
+	}
+	return []string{e.name, e.name + "_bucket", e.name + "_sum", e.name + "_count"}
+}
+
+func (h *MetricsLogHandler) HandleLog(log Log) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	verbosity := log.Verbosity
+	if verbosity == "" {
+		verbosity = "Log"
+	}
+	h.logCounts[metricsLogKey{category: log.Category, verbosity: verbosity}]++
+	if h.fingerprinter != nil && h.templateLimit > 0 {
+		templateID, _ := h.fingerprinter.Fingerprint(log)
+		if _, ok := h.templateCounts[templateID]; !ok && len(h.templateCounts) >= h.templateLimit {
+			templateID = MetricsOtherTemplateID
+		}
+		h.templateCounts[templateID]++
+	}
+
+	// Only the header is decoded since bodies are not needed to count payloads by Meta.Type
+	for _, payload := range FindStructuredPayloads(log.Log) {
+		result, err := JSONToStructuredData[structuredLogHeader, structuredLogBody](payload.JSON)
+		if err != nil {
+			h.structuredErrors++
+			continue
+		}
+		h.structuredCounts[result.Meta.Type]++
+	}
+
+	for _, extractor := range h.extractors {
+		matches := extractor.pattern.FindStringSubmatch(log.Log)
+		if len(matches) < 2 {
+			continue
+		}
+		value, err := strconv.ParseFloat(matches[1], 64)
+		if err != nil {
+			continue
+		}
+		extractor.observe(value)
+	}
+
+	return nil
+}
+
+func (e *metricsExtractor) observe(value float64) {
+	if !e.histogram {
+		e.value = value
+		e.set = true
+		return
+	}
+
+	for i, bound := range e.buckets {
+		if value <= bound {
+			e.bucketCounts[i]++
+		}
+	}
+	e.count++
+	e.sum += value
+}
+
+func (h *MetricsLogHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Metrics are written to buffer first so that errors can be responded with status
+	var body bytes.Buffer
+	if err := h.WriteMetrics(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(body.Bytes())
+}
+
+// WriteMetrics Write metrics in Prometheus text format
+func (h *MetricsLogHandler) WriteMetrics(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+
+	name := h.namespace + "_logs_total"
+	writeMetricHeader(bw, name, "Number of logs by category and verbosity.", "counter")
+	logKeys := make([]metricsLogKey, 0, len(h.logCounts))
+	for key := range h.logCounts {
+		logKeys = append(logKeys, key)
+	}
+	sort.Slice(logKeys, func(i, j int) bool {
+		if logKeys[i].category != logKeys[j].category {
+			return logKeys[i].category < logKeys[j].category
+		}
+		return logKeys[i].verbosity < logKeys[j].verbosity
+	})
+	for _, key := range logKeys {
+		fmt.Fprintf(bw, "%s{category=%s,verbosity=%s} %d\n", name, quoteLabelValue(key.category), quoteLabelValue(key.verbosity), h.logCounts[key])
+	}
+
+	name = h.namespace + "_structured_logs_total"
+	writeMetricHeader(bw, name, "Number of structured log payloads by type.", "counter")
+	types := make([]string, 0, len(h.structuredCounts))
+	for structureType := range h.structuredCounts {
+		types = append(types, structureType)
+	}
+	sort.Strings(types)
+	for _, structureType := range types {
+		fmt.Fprintf(bw, "%s{type=%s} %d\n", name, quoteLabelValue(structureType), h.structuredCounts[structureType])
+	}
+
+	name = h.namespace + "_structured_log_errors_total"
+	writeMetricHeader(bw, name, "Number of structured log payloads which could not be parsed.", "counter")
+	fmt.Fprintf(bw, "%s %d\n", name, h.structuredErrors)
+
+	if len(h.templateCounts) > 0 {
+		name = h.namespace + "_log_templates_total"
+		writeMetricHeader(bw, name, "Number of logs by message template.", "counter")
+		ids := make([]string, 0, len(h.templateCounts))
+		for id := range h.templateCounts {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			fmt.Fprintf(bw, "%s{template_id=%s} %d\n", name, quoteLabelValue(id), h.templateCounts[id])
+		}
+	}
+
+	for _, extractor := range h.extractors {
+		extractor.write(bw)
+	}
+
+	return bw.Flush()
+}
+
+func (e *metricsExtractor) write(w io.Writer) {
+	if !e.histogram {
+		writeMetricHeader(w, e.name, e.help, "gauge")
+		if e.set {
+			fmt.Fprintf(w, "%s %s\n", e.name, formatMetricValue(e.value))
+		}
+		return
+	}
+
+	writeMetricHeader(w, e.name, e.help, "histogram")
+	for i, bound := range e.buckets {
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", e.name, formatMetricValue(bound), e.bucketCounts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", e.name, e.count)
+	fmt.Fprintf(w, "%s_sum %s\n", e.name, formatMetricValue(e.sum))
+	fmt.Fprintf(w, "%s_count %d\n", e.name, e.count)
+}
+
+var metricHelpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+var metricLabelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func writeMetricHeader(w io.Writer, name, help, metricType string) {
+	if help != "" {
+		fmt.Fprintf(w, "# HELP %s %s\n", name, metricHelpReplacer.Replace(help))
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+}
+
+func quoteLabelValue(value string) string {
+	return `"` + metricLabelReplacer.Replace(value) + `"`
+}
+
+func formatMetricValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,125 @@
+package ueloghandler_test
+
+import (
+	"io"
+	"math"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+func TestMetricsLogHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := ueloghandler.NewMetricsLogHandler("my-ns")
+	assert.Error(err)
+
+	handler, err := ueloghandler.NewMetricsLogHandler("ue")
+	if !assert.NoError(err) {
+		return
+	}
+	assert.NoError(handler.AddGauge("ue_streaming_hitch_ms", "Last streaming hitch.", regexp.MustCompile(`LogStreaming: .*hitch (\d+)ms`)))
+	// +Inf and duplicated bounds are ignored
+	assert.NoError(handler.AddHistogram("ue_streaming_hitch_duration_ms", "Streaming hitch.", regexp.MustCompile(`LogStreaming: .*hitch (\d+)ms`), []float64{100, math.Inf(1), 50, 100}))
+	assert.Error(handler.AddGauge("ue_streaming_hitch_ms", "", regexp.MustCompile(`(\d+)`)))
+	assert.Error(handler.AddGauge("invalid-name", "", regexp.MustCompile(`(\d+)`)))
+	assert.Error(handler.AddGauge("no_submatch", "", regexp.MustCompile(`\d+`)))
+	// Names of builtin metrics and samples of histograms are reserved
+	assert.Error(handler.AddGauge("ue_logs_total", "", regexp.MustCompile(`(\d+)`)))
+	assert.Error(handler.AddGauge("ue_streaming_hitch_duration_ms_count", "", regexp.MustCompile(`(\d+)`)))
+
+	structured := func(structureType string) string {
+		return ueloghandler.BeginStructuredStr + `{"Meta":{"Type":"` + structureType + `"},"Body":{}}` + ueloghandler.EndStructuredStr
+	}
+
+	logs := []string{
+		"[2022.05.01-17.56.38:615][429]LogStreaming: Warning: Detected hitch 30ms\n",
+		"[2022.05.01-17.56.38:616][429]LogStreaming: Warning: Detected hitch 120ms\n",
+		"[2022.05.01-17.56.38:617][430]LogTemp: " + structured("Damage") + structured("Damage") + "\n",
+		"[2022.05.01-17.56.38:618][430]LogTemp: " + structured("Spawn") + "\n",
+		"[2022.05.01-17.56.38:619][430]LogTemp: Error: " + ueloghandler.BeginStructuredStr + "{invalid}" + ueloghandler.EndStructuredStr + "\n",
+		"Log file open, 05/02/22 02:56:31\n",
+	}
+	for _, log := range logs {
+		assert.NoError(handler.HandleLog(ueloghandler.NewLog(log)))
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal("text/plain; version=0.0.4; charset=utf-8", recorder.Header().Get("Content-Type"))
+	body, err := io.ReadAll(recorder.Body)
+	assert.NoError(err)
+	assert.Equal(`# HELP ue_logs_total Number of logs by category and verbosity.
+# TYPE ue_logs_total counter
+ue_logs_total{category="",verbosity="Log"} 1
+ue_logs_total{category="LogStreaming",verbosity="Warning"} 2
+ue_logs_total{category="LogTemp",verbosity="Error"} 1
+ue_logs_total{category="LogTemp",verbosity="Log"} 2
+# HELP ue_structured_logs_total Number of structured log payloads by type.
+# TYPE ue_structured_logs_total counter
+ue_structured_logs_total{type="Damage"} 2
+ue_structured_logs_total{type="Spawn"} 1
+# HELP ue_structured_log_errors_total Number of structured log payloads which could not be parsed.
+# TYPE ue_structured_log_errors_total counter
+ue_structured_log_errors_total 1
+# HELP ue_streaming_hitch_ms Last streaming hitch.
+# TYPE ue_streaming_hitch_ms gauge
+ue_streaming_hitch_ms 120
+# HELP ue_streaming_hitch_duration_ms Streaming hitch.
+# TYPE ue_streaming_hitch_duration_ms histogram
+ue_streaming_hitch_duration_ms_bucket{le="50"} 1
+ue_streaming_hitch_duration_ms_bucket{le="100"} 1
+ue_streaming_hitch_duration_ms_bucket{le="+Inf"} 2
+ue_streaming_hitch_duration_ms_sum 150
+ue_streaming_hitch_duration_ms_count 2
+`, string(body))
+}
+
+func TestMetricsLogHandlerTemplates(t *testing.T) {
+	assert := assert.New(t)
+
+	logs := []string{
+		"[2022.05.01-17.56.38:615][429]LogHttp: Error: Request timeout 30s\n",
+		"[2022.05.01-17.56.38:616][430]LogHttp: Error: Request timeout 45s\n",
+		"[2022.05.01-17.56.38:617][431]LogTemp: Warning: Warning 1\n",
+		"[2022.05.01-17.56.38:618][432]LogTemp: Display: Display 1\n",
+		"[2022.05.01-17.56.38:619][433]LogHttp: Error: Request timeout 60s\n",
+	}
+
+	// Template IDs are not counted by default
+	handler, err := ueloghandler.NewMetricsLogHandler("ue")
+	if !assert.NoError(err) {
+		return
+	}
+	for _, log := range logs {
+		assert.NoError(handler.HandleLog(ueloghandler.NewLog(log)))
+	}
+	var body strings.Builder
+	assert.NoError(handler.WriteMetrics(&body))
+	assert.NotContains(body.String(), "ue_log_templates_total")
+
+	handler, err = ueloghandler.NewMetricsLogHandler("ue")
+	if !assert.NoError(err) {
+		return
+	}
+	fingerprinter := ueloghandler.NewMaskingFingerprinter()
+	handler.EnableTemplateCounts(fingerprinter, 2)
+	for _, log := range logs {
+		assert.NoError(handler.HandleLog(ueloghandler.NewLog(log)))
+	}
+
+	timeoutID, _ := fingerprinter.Fingerprint(ueloghandler.NewLog(logs[0]))
+	warningID, _ := fingerprinter.Fingerprint(ueloghandler.NewLog(logs[2]))
+
+	body.Reset()
+	assert.NoError(handler.WriteMetrics(&body))
+	// Logs of templates exceeding the limit are counted as other
+	assert.Contains(body.String(), "# TYPE ue_log_templates_total counter\n")
+	assert.Contains(body.String(), `ue_log_templates_total{template_id="`+timeoutID+`"} 3`+"\n")
+	assert.Contains(body.String(), `ue_log_templates_total{template_id="`+warningID+`"} 1`+"\n")
+	assert.Contains(body.String(), `ue_log_templates_total{template_id="other"} 1`+"\n")
+}
@@ -0,0 +1,287 @@
+package ueloghandler
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// NetEventKind Kind of network connection event
+type NetEventKind string
+
+const (
+	// Connection accepted or added
+	NetEventOpen NetEventKind = "Open"
+	// Login request of player
+	NetEventLogin NetEventKind = "Login"
+	// Player joined
+	NetEventJoin NetEventKind = "Join"
+	// Connection timed out
+	NetEventTimeout NetEventKind = "Timeout"
+	// Network failure reported by NetDriver
+	NetEventError NetEventKind = "Error"
+	// Control channel closed
+	NetEventChannelClose NetEventKind = "ChannelClose"
+	// Connection closed
+	NetEventClose NetEventKind = "Close"
+	// Joined player left by closing connection
+	NetEventLeave NetEventKind = "Leave"
+)
+
+// NetEvent Network connection event extracted from LogNet
+type NetEvent struct {
+	Kind NetEventKind
+	// Name of connection. e.g. IpConnection_2147482549. Empty if the event is not related to a connection.
+	Connection string
+	RemoteAddr string
+	Player     string
+	// Error type and message of NetEventError, or reason of NetEventClose and NetEventLeave
+	Detail string
+	Time   string
+	Frame  string
+	// Session given by SessionLogHandler
+	Session int
+}
+
+// NetConnection Timeline of a connection
+type NetConnection struct {
+	Name       string
+	RemoteAddr string
+	Driver     string
+	Player     string
+	UniqueID   string
+	// Log time of open and close. Empty if not detected.
+	OpenTime  string
+	CloseTime string
+	// Reason of close. Detail of the last timeout, error or channel close before close.
+	CloseReason string
+	Events      []NetEvent
+}
+
+type NetConfig struct {
+	// Called for each event
+	OnEvent func(event NetEvent) error
+}
+
+var (
+	netAcceptingPattern    = regexp.MustCompile(`LogNet: NotifyAcceptingConnection accepted from: ([^\s,]+)`)
+	netConnectionPattern   = regexp.MustCompile(`\[UNetConnection\] RemoteAddr: ([^,]+), Name: ([^,]+), Driver: ([^,]+)`)
+	netUniqueIDPattern     = regexp.MustCompile(`UniqueId: ([^,\s]+)`)
+	netOpenPattern         = regexp.MustCompile(`LogNet: (?:AddClientConnection|NotifyAcceptedConnection):`)
+	netLoginPattern        = regexp.MustCompile(`LogNet: Login request: \?Name=([^?\s]+)`)
+	netJoinPattern         = regexp.MustCompile(`LogNet: Join succeeded: (\S+)`)
+	netTimeoutPattern      = regexp.MustCompile(`LogNet: (?:Warning: )?UNetConnection::Tick: Connection TIMED OUT`)
+	netFailurePattern      = regexp.MustCompile(`LogNet: (?:Warning: |Error: )?Network Failure: (\S+)\[(\w+)\]: ([^\r\n]*)`)
+	netChannelClosePattern = regexp.MustCompile(`LogNet: UChannel::CleanUp: ChIndex == 0\. Closing connection`)
+	netClosePattern        = regexp.MustCompile(`LogNet: (?:Warning: )?UNetConnection::Close:`)
+)
+
+// NetLogHandler Track connection lifecycle of LogNet
+//
+// Connections are identified by the remote address while open.
+// Login and join logs do not have the remote address, so they are attributed to the last opened connection without player.
+//
+// Use Connections to answer why a player disconnected.
+//
+//	for _, connection := range handler.Connections() {
+//		if connection.Player == "Player1" {
+//			fmt.Println(connection.CloseTime, connection.CloseReason)
+//		}
+//	}
+type NetLogHandler struct {
+	config      NetConfig
+	mu          sync.Mutex
+	connections []*NetConnection
+	// Open connections by remote address
+	open   map[string]*NetConnection
+	events []NetEvent
+}
+
+func NewNetLogHandler(config NetConfig) *NetLogHandler {
+	return &NetLogHandler{config: config, open: make(map[string]*NetConnection)}
+}
+
+func (h *NetLogHandler) HandleLog(log Log) error {
+	return h.HandleSessionLog(log, 0, SessionPhaseNone)
+}
+
+func (h *NetLogHandler) HandleSessionLog(log Log, session int, phase SessionPhase) error {
+	if log.Category != "LogNet" {
+		return nil
+	}
+
+	h.mu.Lock()
+	events := h.parse(log, session)
+	h.mu.Unlock()
+
+	if h.config.OnEvent != nil {
+		for _, event := range events {
+			if err := h.config.OnEvent(event); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (h *NetLogHandler) parse(log Log, session int) []NetEvent {
+	line := strings.SplitN(log.Log, "\n", 2)[0]
+
+	var events []NetEvent
+	emit := func(kind NetEventKind, connection *NetConnection, player, detail string) {
+		event := NetEvent{Kind: kind, Player: player, Detail: detail, Time: log.Time, Frame: strings.TrimSpace(log.Frame), Session: session}
+		if connection != nil {
+			event.Connection = connection.Name
+			event.RemoteAddr = connection.RemoteAddr
+			if connection.Player != "" {
+				event.Player = connection.Player
+			}
+			connection.Events = append(connection.Events, event)
+		}
+		h.events = append(h.events, event)
+		events = append(events, event)
+	}
+
+	if matches := netAcceptingPattern.FindStringSubmatch(line); matches != nil {
+		if _, ok := h.open[matches[1]]; !ok {
+			emit(NetEventOpen, h.openConnection(matches[1], log), "", "")
+		}
+		return events
+	}
+
+	var connection *NetConnection
+	if matches := netConnectionPattern.FindStringSubmatch(line); matches != nil {
+		remoteAddr, name := strings.TrimSpace(matches[1]), strings.TrimSpace(matches[2])
+		opened := false
+		connection = h.open[remoteAddr]
+		if connection == nil {
+			// Logs of closed connection such as UNetConnection::Cleanup follow UNetConnection::Close
+			connection = h.connectionOfName(name)
+		}
+		if connection == nil {
+			connection, opened = h.openConnection(remoteAddr, log), true
+		}
+		connection.Name = name
+		connection.Driver = strings.TrimSpace(matches[3])
+		if matches := netUniqueIDPattern.FindStringSubmatch(line); matches != nil && matches[1] != "INVALID" && matches[1] != "NULL" {
+			connection.UniqueID = matches[1]
+		}
+		if opened {
+			emit(NetEventOpen, connection, "", "")
+		}
+	}
+
+	switch {
+	case netOpenPattern.MatchString(line):
+		// Open event is emitted when the connection is found first
+	case netLoginPattern.MatchString(line):
+		player := netLoginPattern.FindStringSubmatch(line)[1]
+		connection = h.lastConnectionWithoutPlayer()
+		if connection != nil {
+			connection.Player = player
+		}
+		emit(NetEventLogin, connection, player, "")
+	case netJoinPattern.MatchString(line):
+		player := netJoinPattern.FindStringSubmatch(line)[1]
+		connection = h.connectionOfPlayer(player)
+		emit(NetEventJoin, connection, player, "")
+	case netTimeoutPattern.MatchString(line):
+		if connection != nil {
+			connection.CloseReason = "Timeout"
+		}
+		emit(NetEventTimeout, connection, "", "")
+	case netFailurePattern.MatchString(line):
+		matches := netFailurePattern.FindStringSubmatch(line)
+		detail := matches[2] + ": " + matches[3]
+		if connection != nil {
+			connection.CloseReason = detail
+		}
+		emit(NetEventError, connection, "", detail)
+	case netChannelClosePattern.MatchString(line):
+		if connection != nil && connection.CloseReason == "" {
+			connection.CloseReason = "ChannelClosed"
+		}
+		emit(NetEventChannelClose, connection, "", "")
+	case netClosePattern.MatchString(line):
+		if connection == nil {
+			break
+		}
+		if connection.CloseReason == "" {
+			connection.CloseReason = "Closed"
+		}
+		connection.CloseTime = log.Time
+		delete(h.open, connection.RemoteAddr)
+		emit(NetEventClose, connection, "", connection.CloseReason)
+		if connection.Player != "" && connection.hasJoined() {
+			emit(NetEventLeave, connection, "", connection.CloseReason)
+		}
+	}
+
+	return events
+}
+
+func (h *NetLogHandler) openConnection(remoteAddr string, log Log) *NetConnection {
+	connection := &NetConnection{RemoteAddr: remoteAddr, OpenTime: log.Time}
+	h.connections = append(h.connections, connection)
+	h.open[remoteAddr] = connection
+	return connection
+}
+
+func (h *NetLogHandler) lastConnectionWithoutPlayer() *NetConnection {
+	for i := len(h.connections) - 1; i >= 0; i-- {
+		connection := h.connections[i]
+		if connection.CloseTime == "" && connection.Player == "" {
+			return connection
+		}
+	}
+	return nil
+}
+
+func (h *NetLogHandler) connectionOfName(name string) *NetConnection {
+	for i := len(h.connections) - 1; i >= 0; i-- {
+		if h.connections[i].Name == name {
+			return h.connections[i]
+		}
+	}
+	return nil
+}
+
+func (h *NetLogHandler) connectionOfPlayer(player string) *NetConnection {
+	for i := len(h.connections) - 1; i >= 0; i-- {
+		connection := h.connections[i]
+		if connection.CloseTime == "" && connection.Player == player {
+			return connection
+		}
+	}
+	return nil
+}
+
+func (c *NetConnection) hasJoined() bool {
+	for _, event := range c.Events {
+		if event.Kind == NetEventJoin {
+			return true
+		}
+	}
+	return false
+}
+
+// Connections Get timelines of all connections in order of open
+func (h *NetLogHandler) Connections() []NetConnection {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	connections := make([]NetConnection, 0, len(h.connections))
+	for _, connection := range h.connections {
+		copied := *connection
+		copied.Events = append([]NetEvent{}, connection.Events...)
+		connections = append(connections, copied)
+	}
+	return connections
+}
+
+// Events Get all events including those not related to a connection
+func (h *NetLogHandler) Events() []NetEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]NetEvent{}, h.events...)
+}
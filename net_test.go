@@ -0,0 +1,85 @@
+package ueloghandler_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+func TestNetLogHandler(t *testing.T) {
+	testLog := `[2022.05.02-04.01.53:000][100]LogNet: NotifyAcceptingConnection accepted from: 127.0.0.1:50001
+[2022.05.02-04.01.53:010][100]LogNet: AddClientConnection: Added client connection: [UNetConnection] RemoteAddr: 127.0.0.1:50001, Name: IpConnection_1, Driver: GameNetDriver IpNetDriver_0, IsServer: YES, PC: NULL, Owner: NULL, UniqueId: INVALID
+[2022.05.02-04.01.53:020][101]LogNet: Login request: ?Name=Alice userId: NULL:Alice-1 platform: NULL
+[2022.05.02-04.01.53:030][102]LogNet: Join succeeded: Alice
+[2022.05.02-04.01.54:000][200]LogNet: NotifyAcceptingConnection accepted from: 127.0.0.1:50002
+[2022.05.02-04.01.54:010][200]LogNet: AddClientConnection: Added client connection: [UNetConnection] RemoteAddr: 127.0.0.1:50002, Name: IpConnection_2, Driver: GameNetDriver IpNetDriver_0, IsServer: YES, PC: NULL, Owner: NULL, UniqueId: INVALID
+[2022.05.02-04.01.54:020][201]LogNet: Login request: ?Name=Bob userId: NULL:Bob-2 platform: NULL
+[2022.05.02-04.01.54:030][202]LogNet: Join succeeded: Bob
+[2022.05.02-04.02.54:000][900]LogNet: Warning: UNetConnection::Tick: Connection TIMED OUT. Closing connection.. Elapsed: 60.00, Real: 60.00, Good: 60.00, DriverTime: 120.00, Threshold: 60.00, [UNetConnection] RemoteAddr: 127.0.0.1:50001, Name: IpConnection_1, Driver: GameNetDriver IpNetDriver_0, IsServer: YES, PC: BP_PlayerController_C_0, Owner: BP_PlayerController_C_0, UniqueId: NULL:Alice-1
+[2022.05.02-04.02.54:010][900]LogNet: Warning: Network Failure: GameNetDriver[ConnectionTimeout]: UNetConnection::Tick: Connection TIMED OUT. Closing connection..
+[2022.05.02-04.02.54:020][900]LogNet: UNetConnection::Close: [UNetConnection] RemoteAddr: 127.0.0.1:50001, Name: IpConnection_1, Driver: GameNetDriver IpNetDriver_0, IsServer: YES, PC: BP_PlayerController_C_0, Owner: BP_PlayerController_C_0, UniqueId: NULL:Alice-1, Channels: 11, Time: 2022.05.02-04.02.54
+[2022.05.02-04.02.54:030][900]LogNet: UNetConnection::Cleanup: [UNetConnection] RemoteAddr: 127.0.0.1:50001, Name: IpConnection_1, Driver: GameNetDriver IpNetDriver_0, IsServer: YES, PC: NULL, Owner: NULL, UniqueId: NULL:Alice-1
+[2022.05.02-04.03.00:000][950]LogNet: UChannel::ReceivedSequencedBunch: Bunch.bClose == true. ChIndex == 0. Calling ConditionalCleanUp.
+[2022.05.02-04.03.00:010][950]LogNet: UChannel::CleanUp: ChIndex == 0. Closing connection. [UChannel] ChIndex: 0, Closing: 0 [UNetConnection] RemoteAddr: 127.0.0.1:50002, Name: IpConnection_2, Driver: GameNetDriver IpNetDriver_0, IsServer: YES, PC: BP_PlayerController_C_1, Owner: BP_PlayerController_C_1, UniqueId: NULL:Bob-2
+[2022.05.02-04.03.00:020][950]LogNet: UNetConnection::Close: [UNetConnection] RemoteAddr: 127.0.0.1:50002, Name: IpConnection_2, Driver: GameNetDriver IpNetDriver_0, IsServer: YES, PC: BP_PlayerController_C_1, Owner: BP_PlayerController_C_1, UniqueId: NULL:Bob-2, Channels: 11, Time: 2022.05.02-04.03.00
+[2022.05.02-04.03.01:000][960]LogTemp: Not a network log
+`
+	assert := assert.New(t)
+
+	var kinds []ueloghandler.NetEventKind
+	handler := ueloghandler.NewNetLogHandler(ueloghandler.NetConfig{
+		OnEvent: func(event ueloghandler.NetEvent) error {
+			kinds = append(kinds, event.Kind)
+			return nil
+		},
+	})
+
+	logs, err := ueloghandler.ReadLogs(strings.NewReader(testLog))
+	assert.NoError(err)
+	for _, log := range logs {
+		assert.NoError(handler.HandleLog(log))
+	}
+
+	assert.Equal([]ueloghandler.NetEventKind{
+		ueloghandler.NetEventOpen, ueloghandler.NetEventLogin, ueloghandler.NetEventJoin,
+		ueloghandler.NetEventOpen, ueloghandler.NetEventLogin, ueloghandler.NetEventJoin,
+		ueloghandler.NetEventTimeout, ueloghandler.NetEventError, ueloghandler.NetEventClose, ueloghandler.NetEventLeave,
+		ueloghandler.NetEventChannelClose, ueloghandler.NetEventClose, ueloghandler.NetEventLeave,
+	}, kinds)
+	assert.Len(handler.Events(), len(kinds))
+
+	connections := handler.Connections()
+	assert.Len(connections, 2)
+
+	alice := connections[0]
+	assert.Equal("IpConnection_1", alice.Name)
+	assert.Equal("127.0.0.1:50001", alice.RemoteAddr)
+	assert.Equal("GameNetDriver IpNetDriver_0", alice.Driver)
+	assert.Equal("Alice", alice.Player)
+	assert.Equal("NULL:Alice-1", alice.UniqueID)
+	assert.Equal("2022.05.02-04.01.53:000", alice.OpenTime)
+	assert.Equal("2022.05.02-04.02.54:020", alice.CloseTime)
+	assert.Equal("Timeout", alice.CloseReason)
+	assert.Len(alice.Events, 6)
+	assert.Equal(ueloghandler.NetEvent{
+		Kind:       ueloghandler.NetEventLeave,
+		Connection: "IpConnection_1",
+		RemoteAddr: "127.0.0.1:50001",
+		Player:     "Alice",
+		Detail:     "Timeout",
+		Time:       "2022.05.02-04.02.54:020",
+		Frame:      "900",
+	}, alice.Events[5])
+
+	// Network failure without remote address is not related to a connection
+	failure := handler.Events()[7]
+	assert.Equal("", failure.Connection)
+	assert.Equal("ConnectionTimeout: UNetConnection::Tick: Connection TIMED OUT. Closing connection..", failure.Detail)
+
+	bob := connections[1]
+	assert.Equal("Bob", bob.Player)
+	assert.Equal("ChannelClosed", bob.CloseReason)
+	assert.Equal("2022.05.02-04.03.00:020", bob.CloseTime)
+}
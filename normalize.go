@@ -0,0 +1,52 @@
+package ueloghandler
+
+import "regexp"
+
+// MaskRule Replace variable part of log message matching Pattern with Replacement
+type MaskRule struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// pathPattern Match paths having a separator and a drive, a leading separator or an extension
+//
+// Words joined with a separator such as dates "05/02/22" and "and/or" are not paths.
+var pathPattern = regexp.MustCompile(
+	`\b[A-Za-z]:(?:[\\/][\w.-]+)+[\\/]?` +
+		`|\B[\\/]{1,2}[\w.-]+(?:[\\/][\w.-]+)*[\\/]?` +
+		`|\b[\w.-]+(?:[\\/][\w.-]+)*[\\/][\w.-]*\.[A-Za-z][A-Za-z0-9]*\b`,
+)
+
+// DefaultMaskRules Mask GUIDs, hexadecimal values, paths and numbers
+var DefaultMaskRules = []MaskRule{
+	{regexp.MustCompile(`[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}|\b[0-9A-Fa-f]{32}\b`), "<guid>"},
+	{regexp.MustCompile(`\b0[xX][0-9A-Fa-f]+\b`), "<hex>"},
+	{pathPattern, "<path>"},
+	{regexp.MustCompile(`\d+(?:\.\d+)?`), "<num>"},
+}
+
+// ObjectNameMaskRule Mask names of Blueprint generated class instances. e.g. BP_Enemy_C_123
+//
+// This rule must be applied before the number rule.
+var ObjectNameMaskRule = MaskRule{regexp.MustCompile(`\b\w+_C_\d+\b`), "<object>"}
+
+// Mask Apply rules to message in order
+func Mask(message string, rules []MaskRule) string {
+	for _, rule := range rules {
+		message = rule.Pattern.ReplaceAllString(message, rule.Replacement)
+	}
+	return message
+}
+
+// NormalizeMessage Replace variable parts of log message with placeholders
+//
+// GUIDs, hexadecimal values such as addresses, file and object paths and numbers are replaced,
+// so that the same message with different values is normalized to the same string.
+//
+// Example:
+//
+//	input: Actor BP_Enemy_C_123 failed to load /Game/Maps/Map01 at 0x00007ffe5bc37eef
+//	result: Actor BP_Enemy_C_<num> failed to load <path> at <hex>
+func NormalizeMessage(message string) string {
+	return Mask(message, DefaultMaskRules)
+}
@@ -0,0 +1,35 @@
+package ueloghandler_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+func TestNormalizeMessage(t *testing.T) {
+	type testCase struct {
+		message string
+		want    string
+	}
+	testCases := []testCase{
+		{message: "Actor BP_Enemy_C_123 failed to load /Game/Maps/Map01 at 0x00007ffe5bc37eef", want: "Actor BP_Enemy_C_<num> failed to load <path> at <hex>"},
+		{message: "Hitch 12.5ms", want: "Hitch <num>ms"},
+		{message: "Session 8D3F2A1B-1C2D-4E5F-8A9B-0C1D2E3F4A5B joined", want: "Session <guid> joined"},
+		{message: "Package 0123456789ABCDEF0123456789ABCDEF", want: "Package <guid>"},
+		{message: `Failed to open C:\Project\Saved\Logs\ue.log`, want: "Failed to open <path>"},
+		{message: "Mounted /Script/Engine.Actor and /Game/", want: "Mounted <path> and <path>"},
+		{message: `Mount \\server\share\Game`, want: "Mount <path>"},
+		{message: "Loaded Saved/Config/Engine.ini", want: "Loaded <path>"},
+		{message: "No variable part", want: "No variable part"},
+		// Not paths
+		{message: "Log file open, 05/02/22 13:01:53", want: "Log file open, <num>/<num>/<num> <num>:<num>:<num>"},
+		{message: "Input and/or output", want: "Input and/or output"},
+		{message: "Speed 10 km/h", want: "Speed <num> km/h"},
+		{message: "Ratio 1.5/2.0 TCP/IP.", want: "Ratio <num>/<num> TCP/IP."},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.want, ueloghandler.NormalizeMessage(testCase.message), testCase.message)
+	}
+}
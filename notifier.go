@@ -9,3 +9,11 @@ type Notifier interface {
 	Subscribe(ctx context.Context) error
 	Flush() error
 }
+
+// SourceNotifier Notifier which knows where the logs are read from
+//
+// Watcher sets Log.Source of the notified logs to Source.
+type SourceNotifier interface {
+	Notifier
+	Source() string
+}
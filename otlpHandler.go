@@ -0,0 +1,217 @@
+package ueloghandler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OTLP severity numbers
+// https://opentelemetry.io/docs/specs/otel/logs/data-model/#field-severitynumber
+const (
+	OTLPSeverityTrace = 1
+	OTLPSeverityDebug = 5
+	OTLPSeverityInfo  = 9
+	OTLPSeverityWarn  = 13
+	OTLPSeverityError = 17
+)
+
+// VerbosityToOTLPSeverity Convert Unreal Engine verbosity to OTLP severity number
+//
+// Logs without verbosity are output with ELogVerbosity::Log, so they are treated as info.
+// Fatal is not mapped since NewLog does not parse it as verbosity.
+func VerbosityToOTLPSeverity(verbosity string) int {
+	switch verbosity {
+	case "Error":
+		return OTLPSeverityError
+	case "Warning":
+		return OTLPSeverityWarn
+	case "Verbose":
+		return OTLPSeverityDebug
+	case "VeryVerbose":
+		return OTLPSeverityTrace
+	default:
+		return OTLPSeverityInfo
+	}
+}
+
+const defaultOTLPBatchSize = 512
+
+type OTLPConfig struct {
+	// Endpoint URL of OTLP/HTTP logs receiver. e.g. http://localhost:4318/v1/logs
+	Endpoint string
+	// Additional HTTP headers. e.g. authorization header
+	Headers map[string]string
+	// Value of service.name resource attribute
+	ServiceName string
+	// Value of ue.log.source attribute used when Log.Source is empty. e.g. path to log file
+	Source string
+	// Number of records sent in one request. Default is 512.
+	BatchSize int
+	// Maximum number of records kept for retry when export fails. The oldest records exceeding it are dropped. Default is 10 times BatchSize.
+	MaxBuffered int
+	// Location of log time. Default is UTC.
+	Location *time.Location
+	// HTTP client used for export. Default is http.DefaultClient.
+	Client *http.Client
+	// Retry settings. Default is DefaultRetryConfig.
+	Retry *RetryConfig
+	// Called with errors of exports in background. If nil, the errors are returned by the next Flush.
+	OnError func(err error)
+}
+
+// OTLPLogHandler Export logs to OpenTelemetry collector over OTLP/HTTP JSON encoding
+//
+// Logs are buffered and exported in background when the buffer reaches BatchSize, so HandleLog does not wait for the collector.
+// Records of a failed export are kept and exported again on the next export unless the collector rejected them.
+// Call Flush to export the remaining logs after watching ends.
+type OTLPLogHandler struct {
+	config  OTLPConfig
+	sender  *httpSender
+	records *sendBuffer[otlpLogRecord]
+	flusher *backgroundFlusher
+}
+
+func NewOTLPLogHandler(config OTLPConfig) *OTLPLogHandler {
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultOTLPBatchSize
+	}
+	if config.Location == nil {
+		config.Location = time.UTC
+	}
+	h := &OTLPLogHandler{
+		config:  config,
+		sender:  newHTTPSender(config.Client, config.Headers, config.Retry),
+		records: newSendBuffer[otlpLogRecord](config.BatchSize, config.MaxBuffered),
+	}
+	h.flusher = &backgroundFlusher{flush: h.export, onError: config.OnError}
+	return h
+}
+
+func (h *OTLPLogHandler) HandleLog(log Log) error {
+	if h.records.add(h.newRecord(log)) {
+		h.flusher.trigger()
+	}
+	return nil
+}
+
+// Flush Wait for export in background and export buffered logs
+//
+// Errors of exports in background are returned together if OnError is nil.
+func (h *OTLPLogHandler) Flush(ctx context.Context) error {
+	err := h.flusher.wait(ctx)
+	return joinErrors(err, h.export(ctx))
+}
+
+func (h *OTLPLogHandler) export(ctx context.Context) error {
+	return h.records.flush(sendAll(func(records []otlpLogRecord) error {
+		body, err := json.Marshal(h.newRequest(records))
+		if err != nil {
+			return err
+		}
+
+		_, err = h.sender.post(ctx, h.config.Endpoint, "application/json", body)
+		return err
+	}))
+}
+
+func (h *OTLPLogHandler) newRecord(log Log) otlpLogRecord {
+	record := otlpLogRecord{
+		ObservedTimeUnixNano: strconv.FormatInt(time.Now().UnixNano(), 10),
+		SeverityNumber:       VerbosityToOTLPSeverity(log.Verbosity),
+		SeverityText:         log.Verbosity,
+		Body:                 otlpAnyValue{StringValue: strings.TrimRight(log.Log, "\n")},
+	}
+	if record.SeverityText == "" {
+		record.SeverityText = "Log"
+	}
+
+	if t, err := log.ParseTime(h.config.Location); err == nil {
+		record.TimeUnixNano = strconv.FormatInt(t.UnixNano(), 10)
+	}
+
+	if log.Category != "" {
+		record.Attributes = append(record.Attributes, newOTLPStringAttribute("ue.category", log.Category))
+	}
+	if log.Frame != "" {
+		record.Attributes = append(record.Attributes, newOTLPStringAttribute("ue.frame", strings.TrimSpace(log.Frame)))
+	}
+	if source := logSource(log, h.config.Source); source != "" {
+		record.Attributes = append(record.Attributes, newOTLPStringAttribute("ue.log.source", source))
+	}
+
+	return record
+}
+
+func (h *OTLPLogHandler) newRequest(records []otlpLogRecord) otlpExportRequest {
+	var resource otlpResource
+	if h.config.ServiceName != "" {
+		resource.Attributes = append(resource.Attributes, newOTLPStringAttribute("service.name", h.config.ServiceName))
+	}
+
+	return otlpExportRequest{
+		ResourceLogs: []otlpResourceLogs{
+			{
+				Resource: resource,
+				ScopeLogs: []otlpScopeLogs{
+					{
+						Scope:      otlpScope{Name: handlerPackageName},
+						LogRecords: records,
+					},
+				},
+			},
+		},
+	}
+}
+
+const handlerPackageName = "github.com/y-akahori-ramen/ueLogHandler"
+
+// OTLP/HTTP JSON encoding of ExportLogsServiceRequest
+// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/collector/logs/v1/logs_service.proto
+
+type otlpExportRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano,omitempty"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+func newOTLPStringAttribute(key, value string) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpAnyValue{StringValue: value}}
+}
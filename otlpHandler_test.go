@@ -0,0 +1,189 @@
+package ueloghandler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+type otlpTestRequest struct {
+	ResourceLogs []struct {
+		Resource struct {
+			Attributes []otlpTestKeyValue
+		}
+		ScopeLogs []struct {
+			LogRecords []struct {
+				TimeUnixNano   string
+				SeverityNumber int
+				SeverityText   string
+				Body           struct{ StringValue string }
+				Attributes     []otlpTestKeyValue
+			}
+		}
+	}
+}
+
+type otlpTestKeyValue struct {
+	Key   string
+	Value struct{ StringValue string }
+}
+
+func TestVerbosityToOTLPSeverity(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(ueloghandler.OTLPSeverityError, ueloghandler.VerbosityToOTLPSeverity("Error"))
+	assert.Equal(ueloghandler.OTLPSeverityWarn, ueloghandler.VerbosityToOTLPSeverity("Warning"))
+	assert.Equal(ueloghandler.OTLPSeverityInfo, ueloghandler.VerbosityToOTLPSeverity("Display"))
+	assert.Equal(ueloghandler.OTLPSeverityInfo, ueloghandler.VerbosityToOTLPSeverity(""))
+	assert.Equal(ueloghandler.OTLPSeverityDebug, ueloghandler.VerbosityToOTLPSeverity("Verbose"))
+	assert.Equal(ueloghandler.OTLPSeverityTrace, ueloghandler.VerbosityToOTLPSeverity("VeryVerbose"))
+}
+
+func TestOTLPLogHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	requests := []otlpTestRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("application/json", r.Header.Get("Content-Type"))
+		assert.Equal("Bearer token", r.Header.Get("Authorization"))
+
+		var req otlpTestRequest
+		assert.NoError(json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+	}))
+	defer server.Close()
+
+	handler := ueloghandler.NewOTLPLogHandler(ueloghandler.OTLPConfig{
+		Endpoint:    server.URL,
+		Headers:     map[string]string{"Authorization": "Bearer token"},
+		ServiceName: "Editor",
+		Source:      "ue.log",
+		BatchSize:   2,
+	})
+
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("[2022.05.01-17.56.38:615][429]LogTemp: Warning: WarningLog\n")))
+	assert.Len(requests, 0)
+	// The first batch is exported in background
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("Log file open, 05/02/22 02:56:31\n")))
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("LogInit: Display: Engine initialized\n")))
+	assert.NoError(handler.Flush(context.Background()))
+	assert.NoError(handler.Flush(context.Background()))
+	if !assert.Len(requests, 2) {
+		return
+	}
+
+	resourceLogs := requests[0].ResourceLogs[0]
+	assert.Equal("service.name", resourceLogs.Resource.Attributes[0].Key)
+	assert.Equal("Editor", resourceLogs.Resource.Attributes[0].Value.StringValue)
+
+	records := resourceLogs.ScopeLogs[0].LogRecords
+	if !assert.Len(records, 2) {
+		return
+	}
+
+	wantTime := time.Date(2022, 5, 1, 17, 56, 38, (int)(615*time.Millisecond), time.UTC)
+	assert.Equal(strconv.FormatInt(wantTime.UnixNano(), 10), records[0].TimeUnixNano)
+	assert.Equal(ueloghandler.OTLPSeverityWarn, records[0].SeverityNumber)
+	assert.Equal("Warning", records[0].SeverityText)
+	assert.Equal("[2022.05.01-17.56.38:615][429]LogTemp: Warning: WarningLog", records[0].Body.StringValue)
+	assert.Equal([]otlpTestKeyValue{
+		{Key: "ue.category", Value: struct{ StringValue string }{"LogTemp"}},
+		{Key: "ue.frame", Value: struct{ StringValue string }{"429"}},
+		{Key: "ue.log.source", Value: struct{ StringValue string }{"ue.log"}},
+	}, records[0].Attributes)
+
+	assert.Equal("", records[1].TimeUnixNano)
+	assert.Equal(ueloghandler.OTLPSeverityInfo, records[1].SeverityNumber)
+	assert.Equal("Log", records[1].SeverityText)
+
+	records = requests[1].ResourceLogs[0].ScopeLogs[0].LogRecords
+	if assert.Len(records, 1) {
+		assert.Equal("Display", records[0].SeverityText)
+	}
+}
+
+func TestOTLPLogHandlerError(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	handler := ueloghandler.NewOTLPLogHandler(ueloghandler.OTLPConfig{Endpoint: server.URL})
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("LogTemp: test")))
+	assert.Error(handler.Flush(context.Background()))
+	// Records rejected by the collector are not exported again
+	assert.NoError(handler.Flush(context.Background()))
+}
+
+func TestOTLPLogHandlerRequeue(t *testing.T) {
+	assert := assert.New(t)
+
+	server := newFailingServer(1<<30, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	var errs errorRecorder
+	retry := ueloghandler.RetryConfig{MaxRetries: 0}
+	handler := ueloghandler.NewOTLPLogHandler(ueloghandler.OTLPConfig{Endpoint: server.URL, BatchSize: 2, MaxBuffered: 3, Retry: &retry, OnError: errs.onError})
+	// Failed exports in background do not stop the watcher
+	for _, log := range []string{"LogTemp: test1", "LogTemp: test2", "LogTemp: test3", "LogTemp: test4"} {
+		assert.NoError(handler.HandleLog(ueloghandler.NewLog(log)))
+	}
+	err := handler.Flush(context.Background())
+	if assert.Error(err) {
+		// test1 exceeding MaxBuffered is dropped
+		assert.Contains(errs.messages()+err.Error(), "dropped 1 oldest")
+	}
+
+	// Records of failed exports are kept and exported again
+	server.setFailures(0)
+	assert.NoError(handler.Flush(context.Background()))
+	bodies := server.succeededBodies()
+	if !assert.Len(bodies, 2) {
+		return
+	}
+	assert.NotContains(bodies[0], "test1")
+	assert.Contains(bodies[0], "test2")
+	assert.Contains(bodies[0], "test3")
+	assert.Contains(bodies[1], "test4")
+}
+
+func TestOTLPLogHandlerBackground(t *testing.T) {
+	assert := assert.New(t)
+
+	release := make(chan struct{})
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		// The first request is rejected and the others fail temporarily
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	retry := ueloghandler.RetryConfig{MaxRetries: 0}
+	handler := ueloghandler.NewOTLPLogHandler(ueloghandler.OTLPConfig{Endpoint: server.URL, BatchSize: 1, Retry: &retry})
+	// HandleLog does not wait for the blocked export
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("LogTemp: test1")))
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("LogTemp: test2")))
+	close(release)
+
+	// Errors in background are returned by Flush if OnError is nil
+	err := handler.Flush(context.Background())
+	if assert.Error(err) {
+		// The rejected export is reported together with the temporary failure
+		assert.Contains(err.Error(), "400 Bad Request")
+		assert.Contains(err.Error(), "503 Service Unavailable")
+	}
+}
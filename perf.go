@@ -0,0 +1,238 @@
+package ueloghandler
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PerfKind Kind of performance event
+type PerfKind string
+
+const (
+	// Hitch or stall detected by engine. e.g. LogStreaming, game thread hitch
+	PerfKindHitch PerfKind = "Hitch"
+	// Line of STAT dump
+	PerfKindStat PerfKind = "Stat"
+	// GPU timing reported by LogRHI
+	PerfKindGPU PerfKind = "GPU"
+	// Event of CSV profiler
+	PerfKindCsvProfiler PerfKind = "CsvProfiler"
+)
+
+// PerfRule Extract performance event of Kind from logs matching Pattern
+//
+// Pattern can have the following named submatches.
+//
+//	ms: Duration in milliseconds
+//	seconds: Duration in seconds. Converted to milliseconds.
+//	name: Name of what is measured. e.g. stat name, thread name
+//	frame: Frame number overriding Log.Frame
+type PerfRule struct {
+	Kind    PerfKind
+	Pattern *regexp.Regexp
+}
+
+// DefaultPerfRules Rules for performance warnings printed by Unreal Engine
+var DefaultPerfRules = []PerfRule{
+	{PerfKindHitch, regexp.MustCompile(`LogStreaming: (?:Warning: |Display: )?.*?[Hh]itch.*?(?P<ms>\d+(?:\.\d+)?) ?ms`)},
+	{PerfKindHitch, regexp.MustCompile(`LogCore: (?:Warning: |Error: )?Hitch detected on (?P<name>\w+).*?(?P<ms>\d+(?:\.\d+)?) ?ms`)},
+	{PerfKindHitch, regexp.MustCompile(`LogStats: (?:Warning: |Display: )?.*?[Hh]itch @ (?:frame )?(?P<frame>\d+).*?(?P<ms>\d+(?:\.\d+)?) ?ms`)},
+	{PerfKindStat, regexp.MustCompile(`LogStats: (?:Display: )?\s*(?P<ms>\d+(?:\.\d+)?) ?ms\s.*?(?P<name>STAT_\w+)`)},
+	{PerfKindGPU, regexp.MustCompile(`LogRHI: (?:Warning: |Display: )?.*?(?P<ms>\d+(?:\.\d+)?) ?ms\s+(?P<name>[A-Za-z][^\r\n]*?)\s+\d+ draws`)},
+	{PerfKindGPU, regexp.MustCompile(`LogRHI: (?:Warning: |Display: )?GPU (?:[Tt]ime|[Ff]rame [Tt]ime):? (?P<ms>\d+(?:\.\d+)?) ?ms`)},
+	{PerfKindCsvProfiler, regexp.MustCompile(`LogCsvProfiler: (?:Display: )?(?P<name>Capture (?:Queued|Started|Ended))(?:.*?Duration:? (?P<seconds>\d+(?:\.\d+)?) ?s)?`)},
+}
+
+// DefaultPerfBuckets Upper bounds of histogram buckets in milliseconds
+var DefaultPerfBuckets = []float64{33, 50, 100, 250, 500, 1000}
+
+// PerfEvent Performance event extracted from log
+type PerfEvent struct {
+	Kind         PerfKind
+	Name         string
+	Milliseconds float64
+	Time         string
+	Frame        string
+	// Session and phase given by SessionLogHandler
+	Session int
+	Phase   SessionPhase
+}
+
+// PerfHistogram Distribution of durations of events of a kind in a session
+type PerfHistogram struct {
+	Session           int
+	Kind              PerfKind
+	Count             int
+	TotalMilliseconds float64
+	MaxMilliseconds   float64
+	// Counts of events whose duration is in (previous bound, UpperBound]
+	Buckets []PerfBucket
+	// Count of events longer than the last bucket
+	Overflow int
+}
+
+type PerfBucket struct {
+	UpperBound float64
+	Count      int
+}
+
+// PerfReport Performance events and their histograms
+type PerfReport struct {
+	Events     []PerfEvent
+	Histograms []PerfHistogram
+}
+
+type PerfConfig struct {
+	// Default is DefaultPerfRules.
+	Rules []PerfRule
+	// Bucket upper bounds in milliseconds. Default is DefaultPerfBuckets.
+	Buckets []float64
+	// Called for each extracted event
+	OnEvent func(event PerfEvent) error
+}
+
+// PerfLogHandler Extract hitches and performance warnings from logs
+//
+// Put SessionLogHandler in front of this handler to get histograms per session.
+// Call Report to get the events after handling logs.
+type PerfLogHandler struct {
+	config PerfConfig
+	mu     sync.Mutex
+	events []PerfEvent
+}
+
+func NewPerfLogHandler(config PerfConfig) *PerfLogHandler {
+	if config.Rules == nil {
+		config.Rules = DefaultPerfRules
+	}
+	if config.Buckets == nil {
+		config.Buckets = DefaultPerfBuckets
+	}
+	config.Buckets = append([]float64{}, config.Buckets...)
+	sort.Float64s(config.Buckets)
+	return &PerfLogHandler{config: config}
+}
+
+func (h *PerfLogHandler) HandleLog(log Log) error {
+	return h.HandleSessionLog(log, 0, SessionPhaseNone)
+}
+
+func (h *PerfLogHandler) HandleSessionLog(log Log, session int, phase SessionPhase) error {
+	// Lines without time such as callstacks are grouped into one log. Each line is matched separately.
+	for _, line := range strings.Split(strings.TrimRight(log.Log, "\n"), "\n") {
+		for _, rule := range h.config.Rules {
+			event, ok := rule.extract(log, line, session, phase)
+			if !ok {
+				continue
+			}
+
+			h.mu.Lock()
+			h.events = append(h.events, event)
+			h.mu.Unlock()
+
+			if h.config.OnEvent != nil {
+				if err := h.config.OnEvent(event); err != nil {
+					return err
+				}
+			}
+			// Only the first matched rule is used for a line
+			break
+		}
+	}
+	return nil
+}
+
+func (r *PerfRule) extract(log Log, line string, session int, phase SessionPhase) (PerfEvent, bool) {
+	matches := r.Pattern.FindStringSubmatch(line)
+	if matches == nil {
+		return PerfEvent{}, false
+	}
+
+	event := PerfEvent{Kind: r.Kind, Time: log.Time, Frame: strings.TrimSpace(log.Frame), Session: session, Phase: phase}
+	for i, name := range r.Pattern.SubexpNames() {
+		value := strings.TrimSpace(matches[i])
+		if value == "" {
+			continue
+		}
+		switch name {
+		case "ms", "seconds":
+			duration, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return PerfEvent{}, false
+			}
+			if name == "seconds" {
+				duration *= 1000
+			}
+			event.Milliseconds = duration
+		case "name":
+			event.Name = value
+		case "frame":
+			event.Frame = value
+		}
+	}
+	return event, true
+}
+
+// Report Get extracted events and histograms sorted by session and kind
+//
+// Events without duration are not counted in histograms.
+func (h *PerfLogHandler) Report() PerfReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	report := PerfReport{Events: append([]PerfEvent{}, h.events...), Histograms: []PerfHistogram{}}
+
+	type histogramKey struct {
+		session int
+		kind    PerfKind
+	}
+	histograms := map[histogramKey]*PerfHistogram{}
+	for _, event := range h.events {
+		if event.Milliseconds <= 0 {
+			continue
+		}
+
+		key := histogramKey{session: event.Session, kind: event.Kind}
+		histogram, ok := histograms[key]
+		if !ok {
+			histogram = &PerfHistogram{Session: event.Session, Kind: event.Kind, Buckets: make([]PerfBucket, len(h.config.Buckets))}
+			for i, bound := range h.config.Buckets {
+				histogram.Buckets[i].UpperBound = bound
+			}
+			histograms[key] = histogram
+		}
+		histogram.observe(event.Milliseconds)
+	}
+
+	for _, histogram := range histograms {
+		report.Histograms = append(report.Histograms, *histogram)
+	}
+	sort.Slice(report.Histograms, func(i, j int) bool {
+		a, b := report.Histograms[i], report.Histograms[j]
+		if a.Session != b.Session {
+			return a.Session < b.Session
+		}
+		return a.Kind < b.Kind
+	})
+
+	return report
+}
+
+func (h *PerfHistogram) observe(milliseconds float64) {
+	h.Count++
+	h.TotalMilliseconds += milliseconds
+	if milliseconds > h.MaxMilliseconds {
+		h.MaxMilliseconds = milliseconds
+	}
+
+	for i := range h.Buckets {
+		if milliseconds <= h.Buckets[i].UpperBound {
+			h.Buckets[i].Count++
+			return
+		}
+	}
+	h.Overflow++
+}
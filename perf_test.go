@@ -0,0 +1,83 @@
+package ueloghandler_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+func TestPerfLogHandler(t *testing.T) {
+	testLog := `Log file open, 05/02/22 13:01:53
+[2022.05.02-04.01.53:149][100]LogStreaming: Warning: Detected a hitch of 120.5ms while flushing async loading
+[2022.05.02-04.01.54:000][200]LogCore: Warning: Hitch detected on gamethread (frame hasn't finished for 40.0ms):
+[2022.05.02-04.01.55:000][300]LogStats: Warning: Hitch @ frame 295 took 600ms
+[2022.05.02-04.01.56:000][400]LogStats:   16.67ms ( 1) - Frame time - STAT_FrameTime
+[2022.05.02-04.01.56:000][400]LogStats:   8.20ms ( 1) - Game thread - STAT_GameEngineTick
+[2022.05.02-04.01.57:000][500]LogRHI: Display:   100.0% 12.34ms   FRAME 1 draws 100 prims 200 verts
+[2022.05.02-04.01.58:000][600]LogCsvProfiler: Display: Capture Started
+[2022.05.02-04.01.59:000][700]LogCsvProfiler: Display: Capture Ended. Frames: 1234 Duration: 20.5s
+[2022.05.02-04.02.00:000][800]LogTemp: Hitch is not detected 10ms
+`
+	assert := assert.New(t)
+
+	handler := ueloghandler.NewPerfLogHandler(ueloghandler.PerfConfig{})
+	logs, err := ueloghandler.ReadLogs(strings.NewReader(testLog))
+	assert.NoError(err)
+	for _, log := range logs {
+		assert.NoError(handler.HandleLog(log))
+	}
+
+	report := handler.Report()
+	assert.Equal([]ueloghandler.PerfEvent{
+		{Kind: ueloghandler.PerfKindHitch, Milliseconds: 120.5, Time: "2022.05.02-04.01.53:149", Frame: "100"},
+		{Kind: ueloghandler.PerfKindHitch, Name: "gamethread", Milliseconds: 40, Time: "2022.05.02-04.01.54:000", Frame: "200"},
+		{Kind: ueloghandler.PerfKindHitch, Milliseconds: 600, Time: "2022.05.02-04.01.55:000", Frame: "295"},
+		{Kind: ueloghandler.PerfKindStat, Name: "STAT_FrameTime", Milliseconds: 16.67, Time: "2022.05.02-04.01.56:000", Frame: "400"},
+		{Kind: ueloghandler.PerfKindStat, Name: "STAT_GameEngineTick", Milliseconds: 8.2, Time: "2022.05.02-04.01.56:000", Frame: "400"},
+		{Kind: ueloghandler.PerfKindGPU, Name: "FRAME", Milliseconds: 12.34, Time: "2022.05.02-04.01.57:000", Frame: "500"},
+		{Kind: ueloghandler.PerfKindCsvProfiler, Name: "Capture Started", Time: "2022.05.02-04.01.58:000", Frame: "600"},
+		{Kind: ueloghandler.PerfKindCsvProfiler, Name: "Capture Ended", Milliseconds: 20500, Time: "2022.05.02-04.01.59:000", Frame: "700"},
+	}, report.Events)
+
+	assert.Len(report.Histograms, 4)
+	hitch := report.Histograms[2]
+	assert.Equal(ueloghandler.PerfKindHitch, hitch.Kind)
+	assert.Equal(3, hitch.Count)
+	assert.Equal(600.0, hitch.MaxMilliseconds)
+	assert.Equal(760.5, hitch.TotalMilliseconds)
+	assert.Equal([]ueloghandler.PerfBucket{
+		{UpperBound: 33, Count: 0},
+		{UpperBound: 50, Count: 1},
+		{UpperBound: 100, Count: 0},
+		{UpperBound: 250, Count: 1},
+		{UpperBound: 500, Count: 0},
+		{UpperBound: 1000, Count: 1},
+	}, hitch.Buckets)
+	assert.Equal(0, hitch.Overflow)
+}
+
+func TestPerfLogHandlerSession(t *testing.T) {
+	assert := assert.New(t)
+
+	perf := ueloghandler.NewPerfLogHandler(ueloghandler.PerfConfig{Buckets: []float64{100}})
+	handler := ueloghandler.NewSessionLogHandler(ueloghandler.SessionConfig{}, perf)
+
+	logs := []string{
+		"Log file open, 05/02/22 13:01:53",
+		"[2022.05.02-04.01.53:149][100]LogStreaming: Warning: Detected a hitch of 120.5ms",
+		"Log file open, 05/02/22 13:05:00",
+		"[2022.05.02-04.05.01:000][100]LogStreaming: Warning: Detected a hitch of 50ms",
+	}
+	for _, log := range logs {
+		assert.NoError(handler.HandleLog(ueloghandler.NewLog(log)))
+	}
+
+	report := perf.Report()
+	assert.Equal([]ueloghandler.PerfHistogram{
+		{Session: 1, Kind: ueloghandler.PerfKindHitch, Count: 1, TotalMilliseconds: 120.5, MaxMilliseconds: 120.5, Buckets: []ueloghandler.PerfBucket{{UpperBound: 100, Count: 0}}, Overflow: 1},
+		{Session: 2, Kind: ueloghandler.PerfKindHitch, Count: 1, TotalMilliseconds: 50, MaxMilliseconds: 50, Buckets: []ueloghandler.PerfBucket{{UpperBound: 100, Count: 1}}},
+	}, report.Histograms)
+	assert.Equal(ueloghandler.SessionPhaseStartup, report.Events[0].Phase)
+}
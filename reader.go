@@ -0,0 +1,149 @@
+package ueloghandler
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// logGrouper Group lines into logs
+//
+// The log may output multiple lines.
+// Therefore, when the start of the next log output is detected, the string up to that point is as a log.
+type logGrouper struct {
+	sb                 strings.Builder
+	basicFormatSection bool
+}
+
+// push Add a line and call emit for each completed log
+func (g *logGrouper) push(lineStr string, emit func(string)) {
+	logInfo := NewLog(lineStr)
+
+	startBasicFormatLog := logInfo.Category != "" || logInfo.Time != ""
+
+	if g.basicFormatSection {
+		if startBasicFormatLog {
+			emit(g.sb.String())
+			g.sb.Reset()
+			g.sb.WriteString(lineStr)
+		} else {
+			g.sb.WriteString(lineStr)
+		}
+	} else {
+		if startBasicFormatLog {
+			g.sb.Reset()
+			g.sb.WriteString(lineStr)
+			g.basicFormatSection = true
+		} else {
+			emit(lineStr)
+		}
+	}
+}
+
+// flush Emit the log being grouped
+func (g *logGrouper) flush(emit func(string)) {
+	if g.sb.Len() > 0 {
+		emit(g.sb.String())
+	}
+	g.sb.Reset()
+	g.basicFormatSection = false
+}
+
+// Reader Read logs from Unreal Engine log
+//
+// Multi-line logs are grouped in the same way as FileNotifier.
+// Reader reads synchronously until EOF, so it is suitable for processing saved log files.
+//
+// Example:
+//
+//	reader := ueloghandler.NewReader(file)
+//	for reader.Next() {
+//		log := reader.Log()
+//	}
+//	if err := reader.Err(); err != nil {
+//	}
+type Reader struct {
+	r       *bufio.Reader
+	grouper logGrouper
+	pending []string
+	log     Log
+	source  string
+	err     error
+	eof     bool
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Next Advance to the next log. It returns false when reaching EOF or an error occurs.
+func (r *Reader) Next() bool {
+	emit := func(logStr string) {
+		r.pending = append(r.pending, logStr)
+	}
+
+	for len(r.pending) == 0 && !r.eof {
+		lineData, err := r.r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			r.err = err
+			return false
+		}
+
+		if len(lineData) > 0 {
+			r.grouper.push(ToUTF8_LF(lineData), emit)
+		}
+
+		if err == io.EOF {
+			r.eof = true
+			r.grouper.flush(emit)
+		}
+	}
+
+	if len(r.pending) == 0 {
+		return false
+	}
+
+	r.log = NewLog(r.pending[0])
+	r.log.Source = r.source
+	r.pending = r.pending[1:]
+	return true
+}
+
+// SetSource Set Log.Source of logs read after this call
+func (r *Reader) SetSource(source string) {
+	r.source = source
+}
+
+// Log Get the current log
+func (r *Reader) Log() Log {
+	return r.log
+}
+
+// Err Get the error occurred while reading. EOF is not an error.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// All Iterate all logs
+//
+// The signature is the same as iter.Seq[Log], so it can be used with range over func.
+// Check Err after iteration.
+func (r *Reader) All() func(yield func(Log) bool) {
+	return func(yield func(Log) bool) {
+		for r.Next() {
+			if !yield(r.Log()) {
+				return
+			}
+		}
+	}
+}
+
+// ReadLogs Read all logs
+func ReadLogs(r io.Reader) ([]Log, error) {
+	logs := []Log{}
+	reader := NewReader(r)
+	for reader.Next() {
+		logs = append(logs, reader.Log())
+	}
+	return logs, reader.Err()
+}
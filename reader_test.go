@@ -0,0 +1,107 @@
+package ueloghandler_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+func TestReader(t *testing.T) {
+	testLog := `Log file open, 05/02/22 13:01:53
+LogWindows: Failed to load 'aqProf.dll' (GetLastError=126)
+LogInit: line1
+line2
+[2022.05.02-04.01.58:862][970]LogHttp: Warning: warningline1
+warningline2
+[2022.05.02-14.10.33:382][513]LogTemp: Error: Verbosity Error
+[2022.05.02-04.01.58:905][970]Log file closed, 05/02/22 13:01:58`
+
+	wantLog := []string{
+		"Log file open, 05/02/22 13:01:53\n",
+		"LogWindows: Failed to load 'aqProf.dll' (GetLastError=126)\n",
+		"LogInit: line1\nline2\n",
+		"[2022.05.02-04.01.58:862][970]LogHttp: Warning: warningline1\nwarningline2\n",
+		"[2022.05.02-14.10.33:382][513]LogTemp: Error: Verbosity Error\n",
+		"[2022.05.02-04.01.58:905][970]Log file closed, 05/02/22 13:01:58",
+	}
+
+	type testCase struct {
+		name string
+		log  string
+		want []string
+	}
+	testCases := []testCase{
+		{name: "Basic", log: testLog, want: wantLog},
+		{name: "LastLineBreak", log: testLog + "\n", want: append(wantLog[:len(wantLog)-1:len(wantLog)-1], wantLog[len(wantLog)-1]+"\n")},
+		{name: "CRLF", log: strings.ReplaceAll(testLog, "\n", "\r\n"), want: wantLog},
+		{name: "BOM", log: "\ufeff" + testLog, want: wantLog},
+		{name: "Empty", log: "", want: []string{}},
+	}
+
+	for i := range testCases {
+		testCase := testCases[i]
+		t.Run(testCase.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			logs, err := ueloghandler.ReadLogs(strings.NewReader(testCase.log))
+			assert.NoError(err)
+
+			wantLogs := []ueloghandler.Log{}
+			for _, log := range testCase.want {
+				wantLogs = append(wantLogs, ueloghandler.NewLog(log))
+			}
+			assert.Equal(wantLogs, logs)
+		})
+	}
+}
+
+func TestReaderAll(t *testing.T) {
+	assert := assert.New(t)
+
+	reader := ueloghandler.NewReader(strings.NewReader("LogTemp: 1\nLogTemp: 2\nLogTemp: 3\n"))
+	logs := []string{}
+	reader.All()(func(log ueloghandler.Log) bool {
+		logs = append(logs, log.Log)
+		return len(logs) < 2
+	})
+	assert.Equal([]string{"LogTemp: 1\n", "LogTemp: 2\n"}, logs)
+
+	assert.True(reader.Next())
+	assert.Equal("LogTemp: 3\n", reader.Log().Log)
+	assert.False(reader.Next())
+	assert.NoError(reader.Err())
+}
+
+type errReader struct{}
+
+func (errReader) Read(p []byte) (int, error) {
+	return 0, errors.New("read error")
+}
+
+func TestReaderError(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := ueloghandler.ReadLogs(errReader{})
+	assert.EqualError(err, "read error")
+}
+
+func TestWatcherRead(t *testing.T) {
+	assert := assert.New(t)
+
+	logs := []ueloghandler.Log{}
+	watcher := ueloghandler.NewWatcher()
+	watcher.AddLogHandler(ueloghandler.NewLogHandler(func(log ueloghandler.Log) error {
+		logs = append(logs, log)
+		if log.Verbosity == "Error" {
+			return errors.New("handle error")
+		}
+		return nil
+	}))
+
+	err := watcher.Read(strings.NewReader("LogTemp: 1\nLogTemp: Error: 2\nLogTemp: 3\n"))
+	assert.EqualError(err, "handle error")
+	assert.Equal([]ueloghandler.Log{ueloghandler.NewLog("LogTemp: 1\n"), ueloghandler.NewLog("LogTemp: Error: 2\n")}, logs)
+}
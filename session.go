@@ -0,0 +1,225 @@
+package ueloghandler
+
+import (
+	"regexp"
+	"sync"
+)
+
+// SessionPhase Phase of engine session
+type SessionPhase string
+
+const (
+	// Outside of session. e.g. after Log file closed
+	SessionPhaseNone SessionPhase = ""
+	// From Log file open until engine is initialized
+	SessionPhaseStartup SessionPhase = "Startup"
+	// Loading map
+	SessionPhaseMapLoad SessionPhase = "MapLoad"
+	// Engine is running. Editor is idle or game is playing
+	SessionPhaseRunning SessionPhase = "Running"
+	// Play in editor is running
+	SessionPhasePIE SessionPhase = "PIE"
+	// Engine is exiting
+	SessionPhaseShutdown SessionPhase = "Shutdown"
+)
+
+// SessionPatterns Patterns of logs marking session transitions
+//
+// Patterns are matched against Log.Log. nil pattern never matches.
+// Since lines without time such as Log file closed are grouped into the previous log, use (?m) to match them.
+type SessionPatterns struct {
+	Open              *regexp.Regexp
+	EngineInitialized *regexp.Regexp
+	// The first submatch is used as map name if exists
+	MapLoadStart *regexp.Regexp
+	MapLoadEnd   *regexp.Regexp
+	PIEStart     *regexp.Regexp
+	PIEEnd       *regexp.Regexp
+	Shutdown     *regexp.Regexp
+	Close        *regexp.Regexp
+}
+
+// DefaultSessionPatterns Patterns of Unreal Engine editor and game logs
+var DefaultSessionPatterns = SessionPatterns{
+	Open:              regexp.MustCompile(`(?m)^\x{FEFF}?Log file open`),
+	EngineInitialized: regexp.MustCompile(`LogInit: Display: Engine is initialized|LogLoad: \(Engine Initialization\) Total time`),
+	MapLoadStart:      regexp.MustCompile(`LogLoad: LoadMap: ([^?\s]+)`),
+	MapLoadEnd:        regexp.MustCompile(`LogLoad: Took [\d.]+ seconds to LoadMap\(`),
+	PIEStart:          regexp.MustCompile(`LogPlayLevel: Creating play world package`),
+	PIEEnd:            regexp.MustCompile(`LogPlayLevel: Display: Shutting down PIE online subsystems`),
+	Shutdown:          regexp.MustCompile(`LogExit: Preparing to exit|LogCore: Engine exit requested`),
+	Close:             regexp.MustCompile(`(?m)^Log file closed`),
+}
+
+// SessionEvent Transition of session phase
+type SessionEvent struct {
+	// Sequence number of session starting from 1
+	Session int
+	From    SessionPhase
+	To      SessionPhase
+	// Map name when To is SessionPhaseMapLoad
+	Map string
+	// Log which caused the transition
+	Log Log
+}
+
+// SessionHandler Log handler receiving session and phase of logs from SessionLogHandler
+//
+// HandleLog is called when the handler is not behind SessionLogHandler. It is regarded as session 0 and SessionPhaseNone.
+type SessionHandler interface {
+	LogHandler
+	HandleSessionLog(log Log, session int, phase SessionPhase) error
+}
+
+func NewSessionHandler(function func(log Log, session int, phase SessionPhase) error) SessionHandler {
+	return &funcSessionHandler{function: function}
+}
+
+type funcSessionHandler struct {
+	function func(log Log, session int, phase SessionPhase) error
+}
+
+func (h *funcSessionHandler) HandleLog(log Log) error {
+	return h.function(log, 0, SessionPhaseNone)
+}
+
+func (h *funcSessionHandler) HandleSessionLog(log Log, session int, phase SessionPhase) error {
+	return h.function(log, session, phase)
+}
+
+type SessionConfig struct {
+	// Default is DefaultSessionPatterns.
+	Patterns *SessionPatterns
+	// Called on every transition of phase. Returning error stops handling the log.
+	OnTransition func(event SessionEvent) error
+}
+
+// SessionLogHandler Segment logs into sessions and phases
+//
+// A session starts at Log file open and ends at Log file closed.
+// If logs start without Log file open, the session starts in SessionPhaseRunning since the startup is unknown.
+// Session and phase are passed to handler if it implements SessionHandler, such as PerfLogHandler, TimingLogHandler and NetLogHandler.
+// Other handlers receive only the log.
+// Logs starting a phase belong to the new phase, and logs ending a phase belong to the ended phase.
+//
+// Example:
+//
+//	Log file open, 05/02/22 13:01:53                                          Startup
+//	[...]LogInit: Display: Engine is initialized. Leaving FEngineLoop::Init() Startup  -> Running
+//	[...]LogLoad: LoadMap: /Game/Maps/Map01                                   MapLoad
+//	[...]LogLoad: Took 0.5 seconds to LoadMap(/Game/Maps/Map01)               MapLoad  -> Running
+//	[...]LogPlayLevel: Creating play world package: /Game/Maps/UEDPIE_0_Map01 PIE
+//	[...]LogPlayLevel: Display: Shutting down PIE online subsystems           PIE      -> Running
+//	[...]LogExit: Preparing to exit.                                          Shutdown -> None
+//	Log file closed, 05/02/22 13:05:00
+type SessionLogHandler struct {
+	config  SessionConfig
+	handler LogHandler
+	mu      sync.Mutex
+	session int
+	phase   SessionPhase
+	// Phase to return after map load
+	beforeMapLoad SessionPhase
+}
+
+func NewSessionLogHandler(config SessionConfig, handler LogHandler) *SessionLogHandler {
+	if config.Patterns == nil {
+		config.Patterns = &DefaultSessionPatterns
+	}
+	return &SessionLogHandler{config: config, handler: handler}
+}
+
+func (h *SessionLogHandler) HandleLog(log Log) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	patterns := h.config.Patterns
+
+	// Transitions caused by logs starting a phase
+	switch {
+	case matchSessionPattern(patterns.Open, log):
+		if h.phase != SessionPhaseNone {
+			if err := h.transition(SessionPhaseNone, "", log); err != nil {
+				return err
+			}
+		}
+		h.session++
+		if err := h.transition(SessionPhaseStartup, "", log); err != nil {
+			return err
+		}
+	case h.phase == SessionPhaseNone && !matchSessionPattern(patterns.Close, log):
+		h.session++
+		if err := h.transition(SessionPhaseRunning, "", log); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case matchSessionPattern(patterns.MapLoadStart, log):
+		if h.phase != SessionPhaseMapLoad {
+			h.beforeMapLoad = h.phase
+		}
+		mapName := ""
+		if matches := patterns.MapLoadStart.FindStringSubmatch(log.Log); len(matches) > 1 {
+			mapName = matches[1]
+		}
+		if err := h.transition(SessionPhaseMapLoad, mapName, log); err != nil {
+			return err
+		}
+	case matchSessionPattern(patterns.PIEStart, log):
+		if err := h.transition(SessionPhasePIE, "", log); err != nil {
+			return err
+		}
+	case matchSessionPattern(patterns.Shutdown, log):
+		if err := h.transition(SessionPhaseShutdown, "", log); err != nil {
+			return err
+		}
+	}
+
+	if err := h.handle(log); err != nil {
+		return err
+	}
+
+	// Transitions caused by logs ending a phase
+	switch {
+	case matchSessionPattern(patterns.EngineInitialized, log):
+		if h.phase == SessionPhaseStartup {
+			return h.transition(SessionPhaseRunning, "", log)
+		}
+		if h.phase == SessionPhaseMapLoad && h.beforeMapLoad == SessionPhaseStartup {
+			h.beforeMapLoad = SessionPhaseRunning
+		}
+	case matchSessionPattern(patterns.MapLoadEnd, log):
+		if h.phase == SessionPhaseMapLoad {
+			return h.transition(h.beforeMapLoad, "", log)
+		}
+	case matchSessionPattern(patterns.PIEEnd, log):
+		if h.phase == SessionPhasePIE {
+			return h.transition(SessionPhaseRunning, "", log)
+		}
+	case matchSessionPattern(patterns.Close, log):
+		return h.transition(SessionPhaseNone, "", log)
+	}
+
+	return nil
+}
+
+func (h *SessionLogHandler) handle(log Log) error {
+	if sessionHandler, ok := h.handler.(SessionHandler); ok {
+		return sessionHandler.HandleSessionLog(log, h.session, h.phase)
+	}
+	return h.handler.HandleLog(log)
+}
+
+func (h *SessionLogHandler) transition(to SessionPhase, mapName string, log Log) error {
+	from := h.phase
+	h.phase = to
+	if h.config.OnTransition == nil || from == to && to != SessionPhaseMapLoad {
+		return nil
+	}
+	return h.config.OnTransition(SessionEvent{Session: h.session, From: from, To: to, Map: mapName, Log: log})
+}
+
+func matchSessionPattern(pattern *regexp.Regexp, log Log) bool {
+	return pattern != nil && pattern.MatchString(log.Log)
+}
@@ -0,0 +1,103 @@
+package ueloghandler_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+func TestSessionLogHandler(t *testing.T) {
+	testLog := `Log file open, 05/02/22 13:01:53
+[2022.05.02-04.01.53:149][  0]LogConfig: CVar deferred
+[2022.05.02-04.01.54:000][  0]LogLoad: LoadMap: /Game/Maps/Entry?Name=Player
+[2022.05.02-04.01.54:500][  0]LogInit: Display: Engine is initialized. Leaving FEngineLoop::Init()
+[2022.05.02-04.01.55:000][  0]LogLoad: Took 1.0 seconds to LoadMap(/Game/Maps/Entry)
+[2022.05.02-04.01.56:000][ 10]LogPlayLevel: Creating play world package: /Game/Maps/UEDPIE_0_Map01
+[2022.05.02-04.01.57:000][ 20]LogLoad: LoadMap: /Game/Maps/Map02
+[2022.05.02-04.01.58:000][ 30]LogLoad: Took 1.0 seconds to LoadMap(/Game/Maps/Map02)
+[2022.05.02-04.01.59:000][ 40]LogPlayLevel: Display: Shutting down PIE online subsystems
+[2022.05.02-04.02.00:000][ 50]LogTemp: Idle
+[2022.05.02-04.02.01:000][ 60]LogExit: Preparing to exit.
+Log file closed, 05/02/22 13:02:02
+`
+
+	assert := assert.New(t)
+
+	type phase struct {
+		session int
+		phase   ueloghandler.SessionPhase
+	}
+	type transition struct {
+		session  int
+		from, to ueloghandler.SessionPhase
+		mapName  string
+	}
+	var phases []phase
+	var transitions []transition
+
+	handler := ueloghandler.NewSessionLogHandler(ueloghandler.SessionConfig{
+		OnTransition: func(event ueloghandler.SessionEvent) error {
+			transitions = append(transitions, transition{session: event.Session, from: event.From, to: event.To, mapName: event.Map})
+			return nil
+		},
+	}, ueloghandler.NewSessionHandler(func(log ueloghandler.Log, session int, sessionPhase ueloghandler.SessionPhase) error {
+		phases = append(phases, phase{session: session, phase: sessionPhase})
+		return nil
+	}))
+
+	logs, err := ueloghandler.ReadLogs(strings.NewReader(testLog))
+	assert.NoError(err)
+	for _, log := range logs {
+		assert.NoError(handler.HandleLog(log))
+	}
+
+	// Engine restarted and the log file was recreated
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("Log file open, 05/02/22 13:03:00")))
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("[2022.05.02-04.03.01:000][  0]LogConfig: CVar deferred")))
+
+	assert.Equal([]phase{
+		{1, ueloghandler.SessionPhaseStartup},
+		{1, ueloghandler.SessionPhaseStartup},
+		{1, ueloghandler.SessionPhaseMapLoad},
+		{1, ueloghandler.SessionPhaseMapLoad},
+		{1, ueloghandler.SessionPhaseMapLoad},
+		{1, ueloghandler.SessionPhasePIE},
+		{1, ueloghandler.SessionPhaseMapLoad},
+		{1, ueloghandler.SessionPhaseMapLoad},
+		{1, ueloghandler.SessionPhasePIE},
+		{1, ueloghandler.SessionPhaseRunning},
+		{1, ueloghandler.SessionPhaseShutdown},
+		{2, ueloghandler.SessionPhaseStartup},
+		{2, ueloghandler.SessionPhaseStartup},
+	}, phases)
+
+	assert.Equal([]transition{
+		{1, ueloghandler.SessionPhaseNone, ueloghandler.SessionPhaseStartup, ""},
+		{1, ueloghandler.SessionPhaseStartup, ueloghandler.SessionPhaseMapLoad, "/Game/Maps/Entry"},
+		{1, ueloghandler.SessionPhaseMapLoad, ueloghandler.SessionPhaseRunning, ""},
+		{1, ueloghandler.SessionPhaseRunning, ueloghandler.SessionPhasePIE, ""},
+		{1, ueloghandler.SessionPhasePIE, ueloghandler.SessionPhaseMapLoad, "/Game/Maps/Map02"},
+		{1, ueloghandler.SessionPhaseMapLoad, ueloghandler.SessionPhasePIE, ""},
+		{1, ueloghandler.SessionPhasePIE, ueloghandler.SessionPhaseRunning, ""},
+		{1, ueloghandler.SessionPhaseRunning, ueloghandler.SessionPhaseShutdown, ""},
+		{1, ueloghandler.SessionPhaseShutdown, ueloghandler.SessionPhaseNone, ""},
+		{2, ueloghandler.SessionPhaseNone, ueloghandler.SessionPhaseStartup, ""},
+	}, transitions)
+}
+
+func TestSessionLogHandlerWithoutOpen(t *testing.T) {
+	assert := assert.New(t)
+
+	var gotSession int
+	var gotPhase ueloghandler.SessionPhase
+	handler := ueloghandler.NewSessionLogHandler(ueloghandler.SessionConfig{}, ueloghandler.NewSessionHandler(func(log ueloghandler.Log, session int, phase ueloghandler.SessionPhase) error {
+		gotSession, gotPhase = session, phase
+		return nil
+	}))
+
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("[2022.05.02-04.02.00:000][ 50]LogTemp: Idle")))
+	assert.Equal(1, gotSession)
+	assert.Equal(ueloghandler.SessionPhaseRunning, gotPhase)
+}
@@ -0,0 +1,373 @@
+package ueloghandler
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SQLiteColumn Column of structured log table
+//
+// Type is a SQLite type name. e.g. INTEGER, REAL, TEXT, VARCHAR(255)
+// Nested fields of body are flattened with "_". e.g. Position_X
+type SQLiteColumn struct {
+	Name string
+	Type string
+}
+
+// SQLiteTable Table storing structured logs of a structure type
+//
+// The table name is "structured_" + Type.
+// Type logs is reserved since structured_logs stores all payloads, and column log_id is reserved for the reference to logs.
+// SQLite identifiers are case insensitive, so the reserved names are rejected in any case.
+// Use gen.SQLiteSchemaTables to create tables from structuregen schema file.
+type SQLiteTable struct {
+	Type    string
+	Columns []SQLiteColumn
+}
+
+var sqliteIdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// sqliteTypePattern Type name of column definition. Words followed by optional size. e.g. DOUBLE PRECISION, DECIMAL(10, 5)
+var sqliteTypePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(?: [A-Za-z_][A-Za-z0-9_]*)*(?:\( *[+-]?\d+ *(?:, *[+-]?\d+ *)?\))?$`)
+
+func (t SQLiteTable) Name() string {
+	return "structured_" + t.Type
+}
+
+// CreateStatements Get statements to create table and index
+func (t SQLiteTable) CreateStatements() ([]string, error) {
+	if !sqliteIdentifierPattern.MatchString(t.Type) {
+		return nil, fmt.Errorf("invalid table name: %s", t.Type)
+	}
+	if strings.EqualFold(t.Name(), "structured_logs") {
+		return nil, fmt.Errorf("reserved table name: %s", t.Name())
+	}
+
+	columns := []string{"log_id INTEGER NOT NULL REFERENCES logs(id)"}
+	for _, column := range t.Columns {
+		if !sqliteIdentifierPattern.MatchString(column.Name) {
+			return nil, fmt.Errorf("invalid column name: %s.%s", t.Type, column.Name)
+		}
+		if strings.EqualFold(column.Name, "log_id") {
+			return nil, fmt.Errorf("reserved column name: %s.%s", t.Type, column.Name)
+		}
+		if !sqliteTypePattern.MatchString(column.Type) {
+			return nil, fmt.Errorf("invalid column type: %s.%s %s", t.Type, column.Name, column.Type)
+		}
+		columns = append(columns, fmt.Sprintf("%s %s", column.Name, column.Type))
+	}
+
+	return []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", t.Name(), strings.Join(columns, ", ")),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_log_id ON %s(log_id)", t.Name(), t.Name()),
+	}, nil
+}
+
+// SQLiteLogsSchema Statements to create logs table
+//
+// logs: All logs. time is stored in "YYYY-MM-DD HH:MM:SS.SSS" format to be used with SQLite date and time functions.
+// structured_logs: All structured log payloads as json. Use json_extract to query types without table.
+var SQLiteLogsSchema = []string{
+	`CREATE TABLE IF NOT EXISTS logs (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	time TEXT,
+	frame INTEGER,
+	category TEXT,
+	verbosity TEXT,
+	source TEXT,
+	message TEXT NOT NULL
+)`,
+	"CREATE INDEX IF NOT EXISTS idx_logs_time ON logs(time)",
+	"CREATE INDEX IF NOT EXISTS idx_logs_category ON logs(category)",
+	"CREATE INDEX IF NOT EXISTS idx_logs_verbosity ON logs(verbosity)",
+	`CREATE TABLE IF NOT EXISTS structured_logs (
+	log_id INTEGER NOT NULL REFERENCES logs(id),
+	type TEXT NOT NULL,
+	json TEXT NOT NULL
+)`,
+	"CREATE INDEX IF NOT EXISTS idx_structured_logs_log_id ON structured_logs(log_id)",
+	"CREATE INDEX IF NOT EXISTS idx_structured_logs_type ON structured_logs(type)",
+}
+
+const sqliteTimeLayout = "2006-01-02 15:04:05.000"
+
+const defaultSQLiteBatchSize = 1000
+
+type SQLiteConfig struct {
+	// Value of source column used when Log.Source is empty. e.g. path to log file
+	Source string
+	// Tables storing structured logs per structure type
+	Tables []SQLiteTable
+	// Number of logs inserted in one transaction. Default is 1000.
+	BatchSize int
+	// Location of log time. Default is UTC.
+	Location *time.Location
+	// Called with errors of inserting a log. If nil, the errors are returned by the next Flush.
+	OnError func(log Log, err error)
+}
+
+// SQLiteLogHandler Store logs to SQLite database
+//
+// The database is opened by the caller with any SQLite driver.
+// Logs are inserted in a transaction which is committed every BatchSize logs.
+// Each log is inserted under a savepoint, so a failed insert discards only the log and keeps the other logs of the transaction.
+// Errors of inserting a log are passed to OnError and do not stop the watcher.
+// Payloads which are not valid structured log JSON are kept only in message of logs,
+// and payloads whose body does not match or can not be inserted into the table are kept only in structured_logs.
+// Call Flush to commit the remaining logs after watching ends.
+type SQLiteLogHandler struct {
+	db     *sql.DB
+	config SQLiteConfig
+	tables map[string]sqliteTableInsert
+
+	mu    sync.Mutex
+	tx    *sql.Tx
+	count int
+	// Insert errors kept for Flush if OnError is nil
+	err error
+}
+
+type sqliteTableInsert struct {
+	query   string
+	columns []string
+}
+
+func NewSQLiteLogHandler(ctx context.Context, db *sql.DB, config SQLiteConfig) (*SQLiteLogHandler, error) {
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultSQLiteBatchSize
+	}
+	if config.Location == nil {
+		config.Location = time.UTC
+	}
+
+	statements := append([]string{}, SQLiteLogsSchema...)
+	tables := map[string]sqliteTableInsert{}
+	for _, table := range config.Tables {
+		createStatements, err := table.CreateStatements()
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, createStatements...)
+
+		columns := []string{"log_id"}
+		for _, column := range table.Columns {
+			columns = append(columns, column.Name)
+		}
+		tables[table.Type] = sqliteTableInsert{
+			query:   fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table.Name(), strings.Join(columns, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")),
+			columns: columns[1:],
+		}
+	}
+
+	for _, statement := range statements {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return nil, err
+		}
+	}
+
+	return &SQLiteLogHandler{db: db, config: config, tables: tables}, nil
+}
+
+func (h *SQLiteLogHandler) HandleLog(log Log) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ctx := context.Background()
+	if h.tx == nil {
+		tx, err := h.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		h.tx = tx
+	}
+
+	if _, err := h.tx.ExecContext(ctx, "SAVEPOINT log"); err != nil {
+		return err
+	}
+	if err := h.insert(ctx, log); err != nil {
+		if _, rollbackErr := h.tx.ExecContext(ctx, "ROLLBACK TO log"); rollbackErr != nil {
+			return rollbackErr
+		}
+		if _, releaseErr := h.tx.ExecContext(ctx, "RELEASE log"); releaseErr != nil {
+			return releaseErr
+		}
+		h.reportInsertError(log, err)
+		return nil
+	}
+	if _, err := h.tx.ExecContext(ctx, "RELEASE log"); err != nil {
+		return err
+	}
+
+	h.count++
+	if h.count >= h.config.BatchSize {
+		return h.commit()
+	}
+	return nil
+}
+
+func (h *SQLiteLogHandler) reportInsertError(log Log, err error) {
+	if h.config.OnError != nil {
+		h.config.OnError(log, err)
+		return
+	}
+	h.err = joinErrors(h.err, err)
+}
+
+// Flush Commit inserted logs
+//
+// Insert errors since the last Flush are returned together if OnError is nil.
+func (h *SQLiteLogHandler) Flush() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	err := h.err
+	h.err = nil
+	return joinErrors(err, h.commit())
+}
+
+func (h *SQLiteLogHandler) commit() error {
+	if h.tx == nil {
+		return nil
+	}
+	err := h.tx.Commit()
+	h.tx = nil
+	h.count = 0
+	return err
+}
+
+func (h *SQLiteLogHandler) insert(ctx context.Context, log Log) error {
+	var logTime, frame interface{}
+	if t, err := log.ParseTime(h.config.Location); err == nil {
+		logTime = t.UTC().Format(sqliteTimeLayout)
+	}
+	if f, err := strconv.Atoi(strings.TrimSpace(log.Frame)); err == nil {
+		frame = f
+	}
+	verbosity := log.Verbosity
+	if verbosity == "" {
+		verbosity = "Log"
+	}
+
+	result, err := h.tx.ExecContext(ctx,
+		"INSERT INTO logs (time, frame, category, verbosity, source, message) VALUES (?, ?, ?, ?, ?, ?)",
+		logTime, frame, log.Category, verbosity, logSource(log, h.config.Source), strings.TrimRight(log.Log, "\n"),
+	)
+	if err != nil {
+		return err
+	}
+
+	jsons := GetStructuredJsonFromLog(log.Log)
+	if len(jsons) == 0 {
+		return nil
+	}
+
+	logID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	for _, jsonStr := range jsons {
+		if err := h.insertStructured(ctx, log, logID, jsonStr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *SQLiteLogHandler) insertStructured(ctx context.Context, log Log, logID int64, jsonStr string) error {
+	type Header struct {
+		Type string
+	}
+	type Body struct {
+		Body json.RawMessage
+	}
+
+	data, err := JSONToStructuredData[Header, Body](jsonStr)
+	if err != nil {
+		// Malformed payload is kept in message of logs
+		return nil
+	}
+
+	_, err = h.tx.ExecContext(ctx, "INSERT INTO structured_logs (log_id, type, json) VALUES (?, ?, ?)", logID, data.Meta.Type, jsonStr)
+	if err != nil {
+		return err
+	}
+
+	table, ok := h.tables[data.Meta.Type]
+	if !ok {
+		return nil
+	}
+
+	fields := map[string]interface{}{}
+	if len(data.Body.Body) != 0 {
+		var body map[string]interface{}
+		decoder := json.NewDecoder(bytes.NewReader(data.Body.Body))
+		decoder.UseNumber()
+		if err := decoder.Decode(&body); err != nil {
+			// Body which is not an object is kept in structured_logs
+			return nil
+		}
+		flattenSQLiteFields(fields, "", body)
+	}
+
+	args := []interface{}{logID}
+	for _, column := range table.columns {
+		args = append(args, fields[column])
+	}
+
+	// Payload failed to be inserted into the table is kept in structured_logs
+	if _, err := h.tx.ExecContext(ctx, "SAVEPOINT structured"); err != nil {
+		return err
+	}
+	if _, err := h.tx.ExecContext(ctx, table.query, args...); err != nil {
+		if _, rollbackErr := h.tx.ExecContext(ctx, "ROLLBACK TO structured"); rollbackErr != nil {
+			return rollbackErr
+		}
+		if _, releaseErr := h.tx.ExecContext(ctx, "RELEASE structured"); releaseErr != nil {
+			return releaseErr
+		}
+		h.reportInsertError(log, fmt.Errorf("insert into structured_%s failed: %w", data.Meta.Type, err))
+		return nil
+	}
+	_, err = h.tx.ExecContext(ctx, "RELEASE structured")
+	return err
+}
+
+func flattenSQLiteFields(dst map[string]interface{}, prefix string, src map[string]interface{}) {
+	for key, value := range src {
+		name := prefix + key
+		switch value := value.(type) {
+		case map[string]interface{}:
+			flattenSQLiteFields(dst, name+"_", value)
+		case json.Number:
+			// uint64 values exceeding int64 are stored as text to keep precision
+			if i, err := value.Int64(); err == nil {
+				dst[name] = i
+			} else if _, err := strconv.ParseUint(value.String(), 10, 64); err == nil {
+				dst[name] = value.String()
+			} else if f, err := value.Float64(); err == nil {
+				dst[name] = f
+			} else {
+				dst[name] = value.String()
+			}
+		case bool:
+			if value {
+				dst[name] = 1
+			} else {
+				dst[name] = 0
+			}
+		case []interface{}:
+			jsonStr, _ := json.Marshal(value)
+			dst[name] = string(jsonStr)
+		default:
+			dst[name] = value
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package ueloghandler_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+func openTestSQLite(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "logs.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func queryRows(t *testing.T, db *sql.DB, query string) [][]interface{} {
+	rows, err := db.Query(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := [][]interface{}{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, values)
+	}
+	return result
+}
+
+func TestSQLiteLogHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	db := openTestSQLite(t)
+	handler, err := ueloghandler.NewSQLiteLogHandler(context.Background(), db, ueloghandler.SQLiteConfig{
+		Source:    "ue.log",
+		BatchSize: 2,
+		Tables: []ueloghandler.SQLiteTable{
+			{
+				Type: "Damage",
+				Columns: []ueloghandler.SQLiteColumn{
+					{Name: "Damage", Type: "INTEGER"},
+					{Name: "Position_X", Type: "REAL"},
+					{Name: "Position_Y", Type: "REAL"},
+					{Name: "Position_Z", Type: "REAL"},
+				},
+			},
+		},
+	})
+	if !assert.NoError(err) {
+		return
+	}
+
+	damage := `{"Meta":{"Type":"Damage"},"Body":{"Meta":{"Tag":"Test"},"Body":{"Damage":9223372036854775807,"Position":{"X":1.5,"Y":2,"Z":3}}}}`
+	other := `{"Meta":{"Type":"Other"},"Body":{"Meta":{},"Body":{}}}`
+	logs := []string{
+		"[2022.05.01-17.56.38:615][ 29]LogTemp: Warning: WarningLog\n",
+		"[2022.05.01-17.56.38:616][ 30]LogTemp: " + ueloghandler.BeginStructuredStr + damage + ueloghandler.EndStructuredStr + ueloghandler.BeginStructuredStr + other + ueloghandler.EndStructuredStr + "\n",
+		"Log file open, 05/02/22 02:56:31\n",
+	}
+	for _, log := range logs {
+		assert.NoError(handler.HandleLog(ueloghandler.NewLog(log)))
+	}
+	// The first batch is committed
+	assert.Len(queryRows(t, db, "SELECT id FROM logs"), 2)
+	assert.NoError(handler.Flush())
+	assert.NoError(handler.Flush())
+
+	assert.Equal([][]interface{}{
+		{int64(1), "2022-05-01 17:56:38.615", int64(29), "LogTemp", "Warning", "ue.log", "[2022.05.01-17.56.38:615][ 29]LogTemp: Warning: WarningLog"},
+		{int64(3), nil, nil, "", "Log", "ue.log", "Log file open, 05/02/22 02:56:31"},
+	}, queryRows(t, db, "SELECT id, time, frame, category, verbosity, source, message FROM logs WHERE id != 2 ORDER BY id"))
+	assert.Equal([][]interface{}{{"2022-05-01 17:56:38.616", int64(30), "Log"}}, queryRows(t, db, "SELECT time, frame, verbosity FROM logs WHERE id = 2"))
+
+	// Unknown type is stored only in structured_logs
+	assert.Equal([][]interface{}{
+		{int64(2), "Damage", damage},
+		{int64(2), "Other", other},
+	}, queryRows(t, db, "SELECT log_id, type, json FROM structured_logs ORDER BY type"))
+
+	assert.Equal([][]interface{}{
+		{int64(2), int64(9223372036854775807), 1.5, 2.0, 3.0},
+	}, queryRows(t, db, "SELECT log_id, Damage, Position_X, Position_Y, Position_Z FROM structured_Damage"))
+
+	// Payloads are queryable with json_extract
+	assert.Equal([][]interface{}{{"Test"}}, queryRows(t, db, "SELECT json_extract(json, '$.Body.Meta.Tag') FROM structured_logs WHERE type = 'Damage'"))
+}
+
+func TestSQLiteLogHandlerMalformedPayload(t *testing.T) {
+	assert := assert.New(t)
+
+	db := openTestSQLite(t)
+	handler, err := ueloghandler.NewSQLiteLogHandler(context.Background(), db, ueloghandler.SQLiteConfig{
+		BatchSize: 10,
+		Tables:    []ueloghandler.SQLiteTable{{Type: "Damage", Columns: []ueloghandler.SQLiteColumn{{Name: "Damage", Type: "INTEGER"}}}},
+	})
+	if !assert.NoError(err) {
+		return
+	}
+
+	logs := []string{
+		"LogTemp: Before\n",
+		"LogTemp: " + ueloghandler.BeginStructuredStr + `{"Meta":"invalid"}` + ueloghandler.EndStructuredStr + "\n",
+		"LogTemp: " + ueloghandler.BeginStructuredStr + `{"Meta":{"Type":"Damage"},"Body":{"Meta":{},"Body":"invalid"}}` + ueloghandler.EndStructuredStr + "\n",
+		"LogTemp: After\n",
+	}
+	// Broken payloads neither fail the log nor discard the other logs of the batch
+	for _, log := range logs {
+		assert.NoError(handler.HandleLog(ueloghandler.NewLog(log)))
+	}
+	assert.NoError(handler.Flush())
+
+	assert.Len(queryRows(t, db, "SELECT id FROM logs"), 4)
+	assert.Equal([][]interface{}{{"Damage"}}, queryRows(t, db, "SELECT type FROM structured_logs"))
+	assert.Len(queryRows(t, db, "SELECT log_id FROM structured_Damage"), 0)
+}
+
+func TestSQLiteLogHandlerInsertError(t *testing.T) {
+	assert := assert.New(t)
+
+	db := openTestSQLite(t)
+	handler, err := ueloghandler.NewSQLiteLogHandler(context.Background(), db, ueloghandler.SQLiteConfig{
+		BatchSize: 10,
+		Tables:    []ueloghandler.SQLiteTable{{Type: "Damage", Columns: []ueloghandler.SQLiteColumn{{Name: "Damage", Type: "INTEGER"}}}},
+	})
+	if !assert.NoError(err) {
+		return
+	}
+	_, err = db.Exec("CREATE TRIGGER reject_damage BEFORE INSERT ON structured_Damage BEGIN SELECT RAISE(ABORT, 'rejected'); END")
+	if !assert.NoError(err) {
+		return
+	}
+
+	// Insert errors do not stop the watcher and are returned by Flush
+	damage := "LogTemp: " + ueloghandler.BeginStructuredStr + `{"Meta":{"Type":"Damage"},"Body":{"Meta":{},"Body":{"Damage":1}}}` + ueloghandler.EndStructuredStr + "\n"
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog(damage)))
+	err = handler.Flush()
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "structured_Damage")
+	}
+	assert.NoError(handler.Flush())
+
+	// Payload which can not be inserted into the table is kept in structured_logs
+	assert.Len(queryRows(t, db, "SELECT message FROM logs"), 1)
+	assert.Len(queryRows(t, db, "SELECT log_id FROM structured_logs"), 1)
+	assert.Len(queryRows(t, db, "SELECT log_id FROM structured_Damage"), 0)
+}
+
+func TestSQLiteLogHandlerOnError(t *testing.T) {
+	assert := assert.New(t)
+
+	db := openTestSQLite(t)
+	errs := []error{}
+	handler, err := ueloghandler.NewSQLiteLogHandler(context.Background(), db, ueloghandler.SQLiteConfig{
+		BatchSize: 10,
+		OnError: func(log ueloghandler.Log, err error) {
+			assert.Equal("LogTemp: Rejected\n", log.Log)
+			errs = append(errs, err)
+		},
+	})
+	if !assert.NoError(err) {
+		return
+	}
+	_, err = db.Exec("CREATE TRIGGER reject_log BEFORE INSERT ON logs WHEN NEW.message = 'LogTemp: Rejected' BEGIN SELECT RAISE(ABORT, 'rejected'); END")
+	if !assert.NoError(err) {
+		return
+	}
+
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("LogTemp: Before\n")))
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("LogTemp: Rejected\n")))
+	assert.NoError(handler.HandleLog(ueloghandler.NewLog("LogTemp: After\n")))
+	assert.NoError(handler.Flush())
+	assert.Len(errs, 1)
+
+	// Only the failed log is discarded
+	assert.Equal([][]interface{}{{"LogTemp: Before"}, {"LogTemp: After"}}, queryRows(t, db, "SELECT message FROM logs ORDER BY id"))
+}
+
+func TestSQLiteTableInvalidName(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := ueloghandler.SQLiteTable{Type: "Invalid Name"}.CreateStatements()
+	assert.Error(err)
+
+	_, err = ueloghandler.SQLiteTable{Type: "Valid", Columns: []ueloghandler.SQLiteColumn{{Name: "x; DROP TABLE logs", Type: "TEXT"}}}.CreateStatements()
+	assert.Error(err)
+
+	_, err = ueloghandler.SQLiteTable{Type: "Valid", Columns: []ueloghandler.SQLiteColumn{{Name: "x", Type: "TEXT); DROP TABLE logs; --"}}}.CreateStatements()
+	assert.Error(err)
+
+	// structured_logs and log_id are used by SQLiteLogHandler
+	_, err = ueloghandler.SQLiteTable{Type: "Logs"}.CreateStatements()
+	assert.Error(err)
+	_, err = ueloghandler.SQLiteTable{Type: "Valid", Columns: []ueloghandler.SQLiteColumn{{Name: "log_id", Type: "INTEGER"}}}.CreateStatements()
+	assert.Error(err)
+
+	for _, columnType := range []string{"INTEGER", "DOUBLE PRECISION", "VARCHAR(255)", "DECIMAL(10, 5)"} {
+		_, err = ueloghandler.SQLiteTable{Type: "Valid", Columns: []ueloghandler.SQLiteColumn{{Name: "x", Type: columnType}}}.CreateStatements()
+		assert.NoError(err, columnType)
+	}
+}
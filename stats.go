@@ -0,0 +1,169 @@
+package ueloghandler
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CategoryVerbosityCount Number of logs of a category and verbosity
+type CategoryVerbosityCount struct {
+	Category  string
+	Verbosity string
+	Count     int
+}
+
+// RepeatedMessage Warning or error message counted by normalized message
+type RepeatedMessage struct {
+	Category  string
+	Verbosity string
+	// Message normalized by NormalizeMessage
+	Message string
+	// First log of the message
+	Example string
+	Count   int
+}
+
+// Crash Crash detected in log
+type Crash struct {
+	Time  string
+	Frame string
+	// Crash reason lines. e.g. Assertion failed: ...
+	Reason []string
+}
+
+// StatsReport Summary of logs
+type StatsReport struct {
+	TotalLogs   int
+	FirstTime   string
+	LastTime    string
+	FirstFrame  string
+	LastFrame   string
+	Counts      []CategoryVerbosityCount
+	TopMessages []RepeatedMessage
+	Crashes     []Crash
+}
+
+var crashPattern = regexp.MustCompile(`=== Critical error: ===|Fatal error: |Assertion failed: |Unhandled Exception: |appError called: `)
+
+type statsMessageKey struct {
+	category, verbosity, message string
+}
+
+// StatsLogHandler Collect statistics of logs
+//
+// Call Report to get the summary after handling logs.
+type StatsLogHandler struct {
+	mu          sync.Mutex
+	report      StatsReport
+	counts      map[CategoryVerbosityCount]int
+	messages    map[statsMessageKey]*RepeatedMessage
+	lastCrashed bool
+}
+
+func NewStatsLogHandler() *StatsLogHandler {
+	return &StatsLogHandler{
+		counts:   make(map[CategoryVerbosityCount]int),
+		messages: make(map[statsMessageKey]*RepeatedMessage),
+	}
+}
+
+func (h *StatsLogHandler) HandleLog(log Log) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	verbosity := log.Verbosity
+	if verbosity == "" {
+		verbosity = "Log"
+	}
+
+	h.report.TotalLogs++
+	h.counts[CategoryVerbosityCount{Category: log.Category, Verbosity: verbosity}]++
+
+	if log.Time != "" {
+		if h.report.FirstTime == "" {
+			h.report.FirstTime = log.Time
+			h.report.FirstFrame = strings.TrimSpace(log.Frame)
+		}
+		h.report.LastTime = log.Time
+		h.report.LastFrame = strings.TrimSpace(log.Frame)
+	}
+
+	if VerbosityLevel(log.Verbosity) <= VerbosityLevelWarning {
+		message := log.Message()
+		key := statsMessageKey{category: log.Category, verbosity: verbosity, message: NormalizeMessage(message)}
+		if repeated, ok := h.messages[key]; ok {
+			repeated.Count++
+		} else {
+			h.messages[key] = &RepeatedMessage{Category: log.Category, Verbosity: verbosity, Message: key.message, Example: strings.TrimRight(log.Log, "\n"), Count: 1}
+		}
+	}
+
+	h.detectCrash(log)
+
+	return nil
+}
+
+// detectCrash Crash reason is output in multiple logs. Consecutive crash logs are treated as one crash.
+func (h *StatsLogHandler) detectCrash(log Log) {
+	message := log.Message()
+	if !crashPattern.MatchString(message) {
+		// Callstack logs follow crash reason
+		h.lastCrashed = h.lastCrashed && strings.Contains(message, "[Callstack]")
+		return
+	}
+
+	reason := strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+	if h.lastCrashed {
+		crash := &h.report.Crashes[len(h.report.Crashes)-1]
+		crash.Reason = append(crash.Reason, reason)
+	} else {
+		h.report.Crashes = append(h.report.Crashes, Crash{Time: log.Time, Frame: strings.TrimSpace(log.Frame), Reason: []string{reason}})
+	}
+	h.lastCrashed = true
+}
+
+// Report Get summary of handled logs
+//
+// TopMessages contains at most topN messages in descending order of count.
+func (h *StatsLogHandler) Report(topN int) StatsReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	report := h.report
+	report.Crashes = append([]Crash{}, h.report.Crashes...)
+
+	report.Counts = []CategoryVerbosityCount{}
+	for key, count := range h.counts {
+		key.Count = count
+		report.Counts = append(report.Counts, key)
+	}
+	sort.Slice(report.Counts, func(i, j int) bool {
+		a, b := report.Counts[i], report.Counts[j]
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		return VerbosityLevel(a.Verbosity) < VerbosityLevel(b.Verbosity)
+	})
+
+	report.TopMessages = []RepeatedMessage{}
+	for _, repeated := range h.messages {
+		report.TopMessages = append(report.TopMessages, *repeated)
+	}
+	sort.Slice(report.TopMessages, func(i, j int) bool {
+		a, b := report.TopMessages[i], report.TopMessages[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		return a.Message < b.Message
+	})
+	if topN >= 0 && len(report.TopMessages) > topN {
+		report.TopMessages = report.TopMessages[:topN]
+	}
+
+	return report
+}
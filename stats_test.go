@@ -0,0 +1,68 @@
+package ueloghandler_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+func TestStatsLogHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	testLog := `Log file open, 05/02/22 13:01:53
+[2022.05.02-04.01.53:149][  0]LogConfig: CVar deferred
+[2022.05.02-04.01.58:862][970]LogTemp: Warning: Actor BP_Enemy_C_1 failed
+[2022.05.02-04.01.58:863][971]LogTemp: Warning: Actor BP_Enemy_C_2 failed
+[2022.05.02-04.01.58:864][972]LogTemp: Warning: Actor BP_Enemy_C_3 failed
+[2022.05.02-04.01.58:865][972]LogHttp: Error: Request timeout 30s
+[2022.05.02-04.01.59:000][ 10]LogWindows: Error: === Critical error: ===
+[2022.05.02-04.01.59:000][ 10]LogWindows: Error: Assertion failed: IsValid(Actor) [File:D:\Game\Source\Enemy.cpp] [Line: 42]
+[2022.05.02-04.01.59:000][ 10]LogWindows: Error: [Callstack] 0x00007ffe5bc37eef UnrealEditor-Core.dll!UnknownFunction []
+[2022.05.02-04.01.59:000][ 10]LogWindows: Error: Fatal error: [File:D:\Game\Source\Enemy.cpp] [Line: 42]
+[2022.05.02-04.01.59:100][ 11]LogExit: Exiting.
+`
+
+	handler := ueloghandler.NewStatsLogHandler()
+	logs, err := ueloghandler.ReadLogs(strings.NewReader(testLog))
+	assert.NoError(err)
+	for _, log := range logs {
+		assert.NoError(handler.HandleLog(log))
+	}
+
+	report := handler.Report(2)
+	assert.Equal(11, report.TotalLogs)
+	assert.Equal("2022.05.02-04.01.53:149", report.FirstTime)
+	assert.Equal("0", report.FirstFrame)
+	assert.Equal("2022.05.02-04.01.59:100", report.LastTime)
+	assert.Equal("11", report.LastFrame)
+
+	assert.Equal([]ueloghandler.CategoryVerbosityCount{
+		{Category: "", Verbosity: "Log", Count: 1},
+		{Category: "LogConfig", Verbosity: "Log", Count: 1},
+		{Category: "LogExit", Verbosity: "Log", Count: 1},
+		{Category: "LogHttp", Verbosity: "Error", Count: 1},
+		{Category: "LogTemp", Verbosity: "Warning", Count: 3},
+		{Category: "LogWindows", Verbosity: "Error", Count: 4},
+	}, report.Counts)
+
+	assert.Equal([]ueloghandler.RepeatedMessage{
+		{Category: "LogTemp", Verbosity: "Warning", Message: "Actor BP_Enemy_C_<num> failed", Example: "[2022.05.02-04.01.58:862][970]LogTemp: Warning: Actor BP_Enemy_C_1 failed", Count: 3},
+		{Category: "LogHttp", Verbosity: "Error", Message: "Request timeout <num>s", Example: "[2022.05.02-04.01.58:865][972]LogHttp: Error: Request timeout 30s", Count: 1},
+	}, report.TopMessages)
+
+	assert.Equal([]ueloghandler.Crash{
+		{
+			Time:  "2022.05.02-04.01.59:000",
+			Frame: "10",
+			Reason: []string{
+				"=== Critical error: ===",
+				`Assertion failed: IsValid(Actor) [File:D:\Game\Source\Enemy.cpp] [Line: 42]`,
+				`Fatal error: [File:D:\Game\Source\Enemy.cpp] [Line: 42]`,
+			},
+		},
+	}, report.Crashes)
+
+	assert.Len(handler.Report(-1).TopMessages, 6)
+}
@@ -3,28 +3,100 @@ package ueloghandler
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
-	"regexp"
+	"strings"
 )
 
 const BeginStructuredStr = "_BEGIN_STRUCTURED_"
 const EndStructuredStr = "_END_STRUCTURED_"
 
-var getStructuredJsonPattern = regexp.MustCompile(fmt.Sprintf(`^.*?%s(.+?)%s(.*)`, BeginStructuredStr, EndStructuredStr))
+// StructuredPayload JSON payload of structured log
+type StructuredPayload struct {
+	JSON string
+	// Byte range of JSON in log string. logstr[Start:End] == JSON
+	Start int
+	End   int
+}
 
-func GetStructuredJsonFromLog(logstr string) []string {
-	jsonStr := []string{}
+// FindStructuredPayloads Find JSON payloads between BeginStructuredStr and EndStructuredStr
+//
+// The JSON is scanned with balancing braces and strings,
+// so payloads containing EndStructuredStr in string values and payloads spanning multiple lines are found.
+// If the payload is not balanced JSON, the text up to the next EndStructuredStr is returned as the payload
+// so that the caller can report it as invalid.
+func FindStructuredPayloads(logstr string) []StructuredPayload {
+	payloads := []StructuredPayload{}
 
-	targetStr := logstr
+	pos := 0
 	for {
-		matches := getStructuredJsonPattern.FindStringSubmatch(targetStr)
-		if len(matches) == 0 {
+		begin := strings.Index(logstr[pos:], BeginStructuredStr)
+		if begin < 0 {
 			break
 		}
-		jsonStr = append(jsonStr, matches[1])
-		targetStr = matches[2]
+		start := pos + begin + len(BeginStructuredStr)
+
+		if end, ok := scanJSONValue(logstr, start); ok && strings.HasPrefix(logstr[end:], EndStructuredStr) {
+			payloads = append(payloads, StructuredPayload{JSON: logstr[start:end], Start: start, End: end})
+			pos = end + len(EndStructuredStr)
+			continue
+		}
+
+		end := strings.Index(logstr[start:], EndStructuredStr)
+		if end < 0 {
+			break
+		}
+		end += start
+		if end > start {
+			payloads = append(payloads, StructuredPayload{JSON: logstr[start:end], Start: start, End: end})
+		}
+		pos = end + len(EndStructuredStr)
+	}
+
+	return payloads
+}
+
+// scanJSONValue Get end position of JSON object or array starting at start
+func scanJSONValue(str string, start int) (int, bool) {
+	if start >= len(str) || (str[start] != '{' && str[start] != '[') {
+		return 0, false
 	}
 
+	depth := 0
+	inString := false
+	escaped := false
+	for i := start; i < len(str); i++ {
+		c := str[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i + 1, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func GetStructuredJsonFromLog(logstr string) []string {
+	jsonStr := []string{}
+	for _, payload := range FindStructuredPayloads(logstr) {
+		jsonStr = append(jsonStr, payload.JSON)
+	}
 	return jsonStr
 }
 
@@ -54,21 +126,24 @@ func JSONToStructuredData[TMeta, TBody any](jsonStr string) (TStructuredData[TMe
 	}
 }
 
+// GetStructuredDataFromLog Decode all payloads in log
+//
+// Payloads which can not be decoded are skipped, and their errors are returned together as StructuredPayloadErrors
+// with the data of the other payloads.
 func GetStructuredDataFromLog[TMeta, TBody any](logstr string) ([]TStructuredData[TMeta, TBody], error) {
-	jsons := GetStructuredJsonFromLog(logstr)
-	if len(jsons) == 0 {
-		return []TStructuredData[TMeta, TBody]{}, nil
-	}
-
 	data := []TStructuredData[TMeta, TBody]{}
-	for _, jsonStr := range jsons {
-		newData, err := JSONToStructuredData[TMeta, TBody](jsonStr)
+	var errs StructuredPayloadErrors
+	for _, payload := range FindStructuredPayloads(logstr) {
+		newData, err := JSONToStructuredData[TMeta, TBody](payload.JSON)
 		if err != nil {
-			return []TStructuredData[TMeta, TBody]{}, err
+			errs = append(errs, &StructuredPayloadError{Payload: payload, Err: err})
 		} else {
 			data = append(data, newData)
 		}
 	}
 
+	if len(errs) != 0 {
+		return data, errs
+	}
 	return data, nil
 }
@@ -3,6 +3,7 @@ package ueloghandler_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
@@ -34,6 +35,26 @@ func TestGetStructuredJson(t *testing.T) {
 			logStr: `normallog_BEGIN_STRUCTURED_{"Key":"Value1"}_END_STRUCTURED_normallog_BEGIN_STRUCTURED_{"Key":"Value2"}_END_STRUCTURED_normallog`,
 			want:   []string{`{"Key":"Value1"}`, `{"Key":"Value2"}`},
 		},
+		{
+			logStr: `_BEGIN_STRUCTURED_{"Key":"_END_STRUCTURED_ \"}"}_END_STRUCTURED_`,
+			want:   []string{`{"Key":"_END_STRUCTURED_ \"}"}`},
+		},
+		{
+			logStr: "log_BEGIN_STRUCTURED_{\"Key\":\n\"Value1\"\n}_END_STRUCTURED_\n",
+			want:   []string{"{\"Key\":\n\"Value1\"\n}"},
+		},
+		{
+			logStr: `_BEGIN_STRUCTURED_{invalid}_END_STRUCTURED__BEGIN_STRUCTURED_{"Key":"Value2"}_END_STRUCTURED_`,
+			want:   []string{`{invalid}`, `{"Key":"Value2"}`},
+		},
+		{
+			logStr: `_BEGIN_STRUCTURED_{"Key":"Value1"_END_STRUCTURED__BEGIN_STRUCTURED_{"Key":"Value2"}_END_STRUCTURED_`,
+			want:   []string{`{"Key":"Value1"`, `{"Key":"Value2"}`},
+		},
+		{
+			logStr: `_BEGIN_STRUCTURED_{"Key":"Value1"}`,
+			want:   []string{},
+		},
 	}
 
 	for i := range testCases {
@@ -46,6 +67,20 @@ func TestGetStructuredJson(t *testing.T) {
 	}
 }
 
+func TestFindStructuredPayloads(t *testing.T) {
+	assert := assert.New(t)
+
+	logStr := "[2022.05.01-17.56.38:615][429]LogTemp: Damage_BEGIN_STRUCTURED_{\"A\":1}_END_STRUCTURED_ and _BEGIN_STRUCTURED_{\"B\":\n2}_END_STRUCTURED_\n"
+	payloads := ueloghandler.FindStructuredPayloads(logStr)
+	assert.Equal([]ueloghandler.StructuredPayload{
+		{JSON: `{"A":1}`, Start: 63, End: 70},
+		{JSON: "{\"B\":\n2}", Start: 109, End: 117},
+	}, payloads)
+	for _, payload := range payloads {
+		assert.Equal(payload.JSON, logStr[payload.Start:payload.End])
+	}
+}
+
 func TestToStructuredData(t *testing.T) {
 	type Meta struct {
 		Type string
@@ -161,7 +196,8 @@ func TestGetStructuredData(t *testing.T) {
 	type testCase struct {
 		logStr   string
 		wantData []TestStructuredData
-		wantErr  error
+		// Error of each broken payload
+		wantErrs []error
 	}
 	testCases := []testCase{
 		{
@@ -175,7 +211,6 @@ func TestGetStructuredData(t *testing.T) {
 					},
 				},
 			},
-			wantErr: nil,
 		},
 		{
 			logStr: `_BEGIN_STRUCTURED_{"Meta":{"Type":"TypeValue"},"Body":{"Sample":{"X":1,"Y":2,"Z":3}}}_END_STRUCTURED_`,
@@ -191,32 +226,46 @@ func TestGetStructuredData(t *testing.T) {
 					},
 				},
 			},
-			wantErr: nil,
 		},
 		{
 			logStr:   `_BEGIN_STRUCTURED_{"InvalidMeta":{"Type":"TypeValue"},"Body":{"Sample":{"X":1,"Y":2,"Z":3}}}_END_STRUCTURED_`,
 			wantData: []TestStructuredData{},
-			wantErr:  ueloghandler.ErrInvalidStructuredLogFormat,
+			wantErrs: []error{ueloghandler.ErrInvalidStructuredLogFormat},
 		},
 		{
 			logStr:   `_BEGIN_STRUCTURED_{"Meta":{"Type":"TypeValue"},"InvalidData":{"Sample":{"X":1,"Y":2,"Z":3}}}_END_STRUCTURED_`,
 			wantData: []TestStructuredData{},
-			wantErr:  ueloghandler.ErrInvalidStructuredLogFormat,
+			wantErrs: []error{ueloghandler.ErrInvalidStructuredLogFormat},
 		},
 		{
-			logStr:   `_BEGIN_STRUCTURED_{"Meta":{"Type":"TypeValue"},"InvalidData":{"Sample":{"X":1,"Y":2,"Z":3}}}_END_STRUCTURED__BEGIN_STRUCTURED_{"Meta":{"Type":"TypeValue"},"Body":{"Sample":10,"Sample2":"Sample2Value"}}_END_STRUCTURED_`,
-			wantData: []TestStructuredData{},
-			wantErr:  ueloghandler.ErrInvalidStructuredLogFormat,
+			logStr: `_BEGIN_STRUCTURED_{"Meta":{"Type":"TypeValue"},"InvalidData":{"Sample":{"X":1,"Y":2,"Z":3}}}_END_STRUCTURED__BEGIN_STRUCTURED_{"Meta":{"Type":"TypeValue"},"Body":{"Sample":10,"Sample2":"Sample2Value"}}_END_STRUCTURED_`,
+			wantData: []TestStructuredData{
+				{
+					Meta: Meta{Type: "TypeValue"},
+					Body: map[string]interface{}{
+						"Sample":  float64(10),
+						"Sample2": "Sample2Value",
+					},
+				},
+			},
+			wantErrs: []error{ueloghandler.ErrInvalidStructuredLogFormat},
 		},
 		{
-			logStr:   `_BEGIN_STRUCTURED_{"Meta":{"Type":"TypeValue"},"Body":{"Sample":10,"Sample2":"Sample2Value"}}_END_STRUCTURED__BEGIN_STRUCTURED_{"Meta":{"Type":"TypeValue"},"InvalidData":{"Sample":{"X":1,"Y":2,"Z":3}}}_END_STRUCTURED_`,
-			wantData: []TestStructuredData{},
-			wantErr:  ueloghandler.ErrInvalidStructuredLogFormat,
+			logStr: `_BEGIN_STRUCTURED_{"Meta":{"Type":"TypeValue"},"Body":{"Sample":10,"Sample2":"Sample2Value"}}_END_STRUCTURED__BEGIN_STRUCTURED_{"Meta":{"Type":"TypeValue"},"InvalidData":{"Sample":{"X":1,"Y":2,"Z":3}}}_END_STRUCTURED_`,
+			wantData: []TestStructuredData{
+				{
+					Meta: Meta{Type: "TypeValue"},
+					Body: map[string]interface{}{
+						"Sample":  float64(10),
+						"Sample2": "Sample2Value",
+					},
+				},
+			},
+			wantErrs: []error{ueloghandler.ErrInvalidStructuredLogFormat},
 		},
 		{
 			logStr:   `{"Meta":{"Type":"TypeValue"},"InvalidData":{"Sample":{"X":1,"Y":2,"Z":3}}}`,
 			wantData: []TestStructuredData{},
-			wantErr:  nil,
 		},
 	}
 
@@ -225,7 +274,16 @@ func TestGetStructuredData(t *testing.T) {
 		t.Run(fmt.Sprintf("Case%d", i), func(t *testing.T) {
 			assert := assert.New(t)
 			results, err := ueloghandler.GetStructuredDataFromLog[Meta, map[string]interface{}](testCase.logStr)
-			assert.Equal(testCase.wantErr, err)
+			if len(testCase.wantErrs) == 0 {
+				assert.NoError(err)
+			} else {
+				var payloadErrs ueloghandler.StructuredPayloadErrors
+				if assert.ErrorAs(err, &payloadErrs) && assert.Len(payloadErrs, len(testCase.wantErrs)) {
+					for i, wantErr := range testCase.wantErrs {
+						assert.ErrorIs(payloadErrs[i], wantErr)
+					}
+				}
+			}
 
 			resultsTypeConvert := []TestStructuredData{}
 			for _, result := range results {
@@ -235,3 +293,161 @@ func TestGetStructuredData(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONToStructuredDataStrict(t *testing.T) {
+	type Meta struct {
+		Tag string
+	}
+	type Vector struct {
+		X, Y, Z float64
+	}
+	type Body struct {
+		Damage   int32
+		Position Vector
+	}
+	schema := ueloghandler.StructureSchema{Meta: []string{"Tag"}, Body: []string{"Damage", "Position"}}
+
+	type testCase struct {
+		jsonStr   string
+		wantField string
+		wantErr   bool
+	}
+	testCases := []testCase{
+		{jsonStr: `{"Meta":{"Tag":"A"},"Body":{"Damage":10,"Position":{"X":0,"Y":1,"Z":2}}}`},
+		{jsonStr: `{"Meta":{"Tag":"A"},"Body":{"Damage":10,"Position":{"X":0,"Y":1,"Z":2},"Critical":true}}`, wantField: "Body.Critical", wantErr: true},
+		{jsonStr: `{"Meta":{"Tag":"A"},"Body":{"Position":{"X":0,"Y":1,"Z":2}}}`, wantField: "Body.Damage", wantErr: true},
+		{jsonStr: `{"Meta":{},"Body":{"Damage":10,"Position":{"X":0,"Y":1,"Z":2}}}`, wantField: "Meta.Tag", wantErr: true},
+		{jsonStr: `{"Meta":{"Tag":"A"},"Body":{"Damage":"10","Position":{"X":0,"Y":1,"Z":2}}}`, wantField: "Body.Damage", wantErr: true},
+		{jsonStr: `{"Meta":{"Tag":"A"}}`, wantField: "Body", wantErr: true},
+		{jsonStr: `{"Meta":{"Tag":"A"},"Body":{"Damage":10,"Position":{"X":0,"Y":1,"Z":2}},"Extra":1}`, wantField: "Extra", wantErr: true},
+		// Nested structs are checked as well
+		{jsonStr: `{"Meta":{"Tag":"A"},"Body":{"Damage":10,"Position":{"X":0,"Y":1}}}`, wantField: "Body.Position.Z", wantErr: true},
+		{jsonStr: `{"Meta":{"Tag":"A"},"Body":{"Damage":10,"Position":{"X":0,"Y":1,"Z":2,"W":3}}}`, wantField: "Body.Position.W", wantErr: true},
+		// Keys are matched case-insensitively if no field matches exactly as encoding/json does
+		{jsonStr: `{"Meta":{"Tag":"A"},"Body":{"damage":10,"Position":{"X":0,"Y":1,"z":2}}}`},
+		{jsonStr: `{"Meta":{"Tag":"A"},"Body":{"Damage":10,"Position":{"X":0,"Y":1,"z":2,"W":3}}}`, wantField: "Body.Position.W", wantErr: true},
+		{jsonStr: `{"Meta":{"Tag":"A"},"Body":{"Damage":10,"Position":{"X":0,"Y":"1","Z":2}}}`, wantField: "Body.Position.Y", wantErr: true},
+		// NaN written as null is not decoded as zero
+		{jsonStr: `{"Meta":{"Tag":"A"},"Body":{"Damage":10,"Position":{"X":0,"Y":null,"Z":2}}}`, wantField: "Body.Position.Y", wantErr: true},
+		{jsonStr: `{"Meta":{"Tag":"A"},"Body":{"Damage":null,"Position":{"X":0,"Y":1,"Z":2}}}`, wantField: "Body.Damage", wantErr: true},
+	}
+
+	for i := range testCases {
+		testCase := testCases[i]
+		t.Run(fmt.Sprintf("Case%d", i), func(t *testing.T) {
+			assert := assert.New(t)
+			data, err := ueloghandler.JSONToStructuredDataStrict[Meta, Body]("Sample", schema, testCase.jsonStr)
+			if !testCase.wantErr {
+				assert.NoError(err)
+				assert.Equal(Body{Damage: 10, Position: Vector{X: 0, Y: 1, Z: 2}}, data.Body)
+				return
+			}
+
+			var validationErr *ueloghandler.ValidationError
+			if assert.ErrorAs(err, &validationErr) {
+				assert.Equal("Sample", validationErr.Type)
+				assert.Equal(testCase.wantField, validationErr.Field)
+			}
+		})
+	}
+}
+
+func TestJSONToStructuredDataStrictNested(t *testing.T) {
+	type Vector struct {
+		X, Y float64
+	}
+	type Body struct {
+		Path    []Vector
+		Target  *Vector
+		Time    time.Time
+		Labels  map[string]Vector
+		Ignored int `json:"-"`
+	}
+	schema := ueloghandler.NewStructureSchema[struct{}, Body]()
+
+	type testCase struct {
+		jsonStr   string
+		wantField string
+	}
+	testCases := []testCase{
+		{jsonStr: `{"Meta":{},"Body":{"Path":[{"X":0,"Y":1}],"Target":null,"Time":"2022-05-01T17:56:38Z","Labels":{"A":{"X":0,"Y":1}}}}`},
+		{jsonStr: `{"Meta":{},"Body":{"Path":[{"X":0,"Y":1},{"X":0}],"Target":null,"Time":"2022-05-01T17:56:38Z","Labels":{}}}`, wantField: "Body.Path[1].Y"},
+		{jsonStr: `{"Meta":{},"Body":{"Path":[],"Target":{"X":0,"Y":1,"Z":2},"Time":"2022-05-01T17:56:38Z","Labels":{}}}`, wantField: "Body.Target.Z"},
+		{jsonStr: `{"Meta":{},"Body":{"Path":[],"Target":null,"Time":"2022-05-01T17:56:38Z","Labels":{"A":{"Y":1}}}}`, wantField: "Body.Labels.A.X"},
+		{jsonStr: `{"Meta":{},"Body":{"Path":[],"Target":null,"Time":"2022-05-01T17:56:38Z","Labels":{},"Ignored":1}}`, wantField: "Body.Ignored"},
+		{jsonStr: `{"Meta":{},"Body":{"Path":null,"Target":null,"Time":"2022-05-01T17:56:38Z","Labels":null}}`},
+		{jsonStr: `{"Meta":{},"Body":{"Path":[],"Target":null,"Time":null,"Labels":{}}}`, wantField: "Body.Time"},
+	}
+
+	for i := range testCases {
+		testCase := testCases[i]
+		t.Run(fmt.Sprintf("Case%d", i), func(t *testing.T) {
+			assert := assert.New(t)
+			_, err := ueloghandler.JSONToStructuredDataStrict[struct{}, Body]("Sample", schema, testCase.jsonStr)
+			if testCase.wantField == "" {
+				assert.NoError(err)
+				return
+			}
+
+			var validationErr *ueloghandler.ValidationError
+			if assert.ErrorAs(err, &validationErr) {
+				assert.Equal(testCase.wantField, validationErr.Field)
+			}
+		})
+	}
+}
+
+func TestJSONToStructuredDataStrictEmbedded(t *testing.T) {
+	type Vector struct {
+		X, Y float64
+	}
+	type Actor struct {
+		Name     string
+		Position Vector
+	}
+	type Tagged struct {
+		Name string `json:"ActorName"`
+	}
+	type Body struct {
+		Actor
+		*Tagged
+		Damage int32
+	}
+	schema := ueloghandler.NewStructureSchema[struct{}, Body]()
+	assert.ElementsMatch(t, []string{"Name", "Position", "ActorName", "Damage"}, schema.Body)
+
+	type testCase struct {
+		jsonStr   string
+		wantField string
+	}
+	testCases := []testCase{
+		// Fields of embedded structs are promoted
+		{jsonStr: `{"Meta":{},"Body":{"Name":"A","Position":{"X":0,"Y":1},"ActorName":"B","Damage":10}}`},
+		{jsonStr: `{"Meta":{},"Body":{"Name":"A","Position":{"X":0},"ActorName":"B","Damage":10}}`, wantField: "Body.Position.Y"},
+		{jsonStr: `{"Meta":{},"Body":{"Position":{"X":0,"Y":1},"ActorName":"B","Damage":10}}`, wantField: "Body.Name"},
+		// Embedded struct itself is not a field
+		{jsonStr: `{"Meta":{},"Body":{"Actor":{},"Name":"A","Position":{"X":0,"Y":1},"ActorName":"B","Damage":10}}`, wantField: "Body.Actor"},
+	}
+
+	for i := range testCases {
+		testCase := testCases[i]
+		t.Run(fmt.Sprintf("Case%d", i), func(t *testing.T) {
+			assert := assert.New(t)
+			data, err := ueloghandler.JSONToStructuredDataStrict[struct{}, Body]("Sample", schema, testCase.jsonStr)
+			if testCase.wantField == "" {
+				if assert.NoError(err) {
+					assert.Equal("A", data.Body.Actor.Name)
+					assert.Equal(Vector{X: 0, Y: 1}, data.Body.Position)
+					assert.Equal("B", data.Body.Tagged.Name)
+					assert.Equal(int32(10), data.Body.Damage)
+				}
+				return
+			}
+
+			var validationErr *ueloghandler.ValidationError
+			if assert.ErrorAs(err, &validationErr) {
+				assert.Equal(testCase.wantField, validationErr.Field)
+			}
+		})
+	}
+}
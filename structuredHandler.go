@@ -2,15 +2,75 @@ package ueloghandler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 )
 
+var ErrUnknownStructureType = errors.New("ueLogHandler:Unknown structure type")
+
+// StructuredPayloadError Error of a structured log payload
+type StructuredPayloadError struct {
+	Payload StructuredPayload
+	// Meta.Type of the payload. Empty if the payload is malformed.
+	Type string
+	Log  Log
+	Err  error
+}
+
+func (e *StructuredPayloadError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("structured payload at %d-%d: %s", e.Payload.Start, e.Payload.End, e.Err)
+	}
+	return fmt.Sprintf("structured payload at %d-%d (%s): %s", e.Payload.Start, e.Payload.End, e.Type, e.Err)
+}
+
+func (e *StructuredPayloadError) Unwrap() error {
+	return e.Err
+}
+
+// StructuredPayloadErrors Errors of payloads in a log
+type StructuredPayloadErrors []*StructuredPayloadError
+
+func (e StructuredPayloadErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+type StructuredLogHandlerConfig struct {
+	// Handler for payloads whose Meta.Type has no handler. Type of the handler is not used.
+	Fallback StructuredLogDataHandler
+	// Keep handling the remaining payloads and logs when a payload is broken.
+	// Payloads of unknown type, malformed payloads and errors returned by handlers are passed to the callbacks instead of returning error.
+	Lenient bool
+	// Called in lenient mode for payload whose Meta.Type has no handler when Fallback is nil
+	OnUnknownType func(err *StructuredPayloadError)
+	// Called in lenient mode for payload which is not valid structured log JSON, can not be decoded by the handler
+	// or fails validation in strict mode
+	OnMalformed func(err *StructuredPayloadError)
+	// Called in lenient mode for other errors returned by handlers.
+	// If nil, the errors are returned together as StructuredPayloadErrors after all payloads are handled.
+	OnHandlerError func(err *StructuredPayloadError)
+	// Decode payloads with HandleStrict of handlers implementing StrictStructuredLogDataHandler
+	// to catch mismatches between the game build and the handler build.
+	// Other handlers are called with Handle.
+	Strict bool
+}
+
 type StructuredLogHandler struct {
+	config   StructuredLogHandlerConfig
 	handlers map[string]StructuredLogDataHandler
 }
 
 func NewStructuredLogHandler() *StructuredLogHandler {
-	return &StructuredLogHandler{handlers: make(map[string]StructuredLogDataHandler)}
+	return NewStructuredLogHandlerWithConfig(StructuredLogHandlerConfig{})
+}
+
+func NewStructuredLogHandlerWithConfig(config StructuredLogHandlerConfig) *StructuredLogHandler {
+	return &StructuredLogHandler{config: config, handlers: make(map[string]StructuredLogDataHandler)}
 }
 
 func (h *StructuredLogHandler) AddHandler(handler StructuredLogDataHandler) error {
@@ -22,13 +82,21 @@ func (h *StructuredLogHandler) AddHandler(handler StructuredLogDataHandler) erro
 	}
 }
 
+type structuredLogHeader struct {
+	Type string
+	// Version of structure. 0 if the payload is generated before versioning.
+	Version int
+}
+
+// structuredLogBody Body is passed to the handler as is and decoded only once into its typed struct
+type structuredLogBody = json.RawMessage
+
 func (h *StructuredLogHandler) HandleLog(log Log) error {
-	type Header struct {
-		Type string
+	if h.config.Lenient {
+		return h.handleLogLenient(log)
 	}
-	type Body map[string]interface{}
 
-	results, err := GetStructuredDataFromLog[Header, Body](log.Log)
+	results, err := GetStructuredDataFromLog[structuredLogHeader, structuredLogBody](log.Log)
 	if err != nil {
 		return err
 	}
@@ -36,15 +104,11 @@ func (h *StructuredLogHandler) HandleLog(log Log) error {
 	if len(results) != 0 {
 		for _, result := range results {
 			structureType := result.Meta.Type
-			if _, ok := h.handlers[structureType]; !ok {
+			handler, ok := h.handler(structureType)
+			if !ok {
 				return fmt.Errorf("invalid structure type: %s", structureType)
 			} else {
-				jsonStr, err := json.Marshal(result.Body)
-				if err != nil {
-					return err
-				}
-
-				err = h.handlers[structureType].Handle(string(jsonStr), log)
+				err := h.handle(handler, result.Meta, string(result.Body), log)
 				if err != nil {
 					return err
 				}
@@ -56,18 +120,112 @@ func (h *StructuredLogHandler) HandleLog(log Log) error {
 	}
 }
 
+// handleLogLenient Handle each payload independently
+func (h *StructuredLogHandler) handleLogLenient(log Log) error {
+	var errs StructuredPayloadErrors
+	for _, payload := range FindStructuredPayloads(log.Log) {
+		result, err := JSONToStructuredData[structuredLogHeader, structuredLogBody](payload.JSON)
+		if err != nil {
+			if h.config.OnMalformed != nil {
+				h.config.OnMalformed(&StructuredPayloadError{Payload: payload, Log: log, Err: err})
+			}
+			continue
+		}
+
+		structureType := result.Meta.Type
+		handler, ok := h.handler(structureType)
+		if !ok {
+			if h.config.OnUnknownType != nil {
+				h.config.OnUnknownType(&StructuredPayloadError{Payload: payload, Type: structureType, Log: log, Err: ErrUnknownStructureType})
+			}
+			continue
+		}
+
+		if err := h.handle(handler, result.Meta, string(result.Body), log); err != nil {
+			payloadErr := &StructuredPayloadError{Payload: payload, Type: structureType, Log: log, Err: err}
+			switch {
+			case isStructuredDecodeError(err):
+				if h.config.OnMalformed != nil {
+					h.config.OnMalformed(payloadErr)
+				}
+			case h.config.OnHandlerError != nil:
+				h.config.OnHandlerError(payloadErr)
+			default:
+				errs = append(errs, payloadErr)
+			}
+		}
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+// isStructuredDecodeError Check whether err is caused by payload which can not be decoded
+func isStructuredDecodeError(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var validationErr *ValidationError
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.As(err, &validationErr) || errors.Is(err, ErrInvalidStructuredLogFormat)
+}
+
+func (h *StructuredLogHandler) handle(handler StructuredLogDataHandler, header structuredLogHeader, json string, log Log) error {
+	if versionedHandler, ok := handler.(VersionedStructuredLogDataHandler); ok {
+		version := header.Version
+		if version == 0 {
+			version = 1
+		}
+		if version != versionedHandler.Version() {
+			upgraded, err := versionedHandler.Upgrade(version, json)
+			if err != nil {
+				return err
+			}
+			json = upgraded
+		}
+	}
+
+	if strictHandler, ok := handler.(StrictStructuredLogDataHandler); ok && h.config.Strict {
+		return strictHandler.HandleStrict(json, log)
+	}
+	return handler.Handle(json, log)
+}
+
+// handler Get handler of structure type. Fallback is used if no handler is registered.
+func (h *StructuredLogHandler) handler(structureType string) (StructuredLogDataHandler, bool) {
+	if handler, ok := h.handlers[structureType]; ok {
+		return handler, true
+	}
+	if h.config.Fallback != nil {
+		return h.config.Fallback, true
+	}
+	return nil, false
+}
+
 type StructuredLogDataHandler interface {
 	Handle(json string, log Log) error
 	Type() string
 }
 
+// StrictStructuredLogDataHandler Handler supporting strict decoding
+//
+// HandleStrict returns *ValidationError if the payload has unknown fields or lacks fields of the schema.
+type StrictStructuredLogDataHandler interface {
+	StructuredLogDataHandler
+	HandleStrict(json string, log Log) error
+}
+
+// NewStructuredLogDataHandler Create handler decoding payload into TStructuredData[THeader, TBody]
+//
+// In strict mode, all exported fields of THeader and TBody are required.
 func NewStructuredLogDataHandler[THeader, TBody any](typeName string, handleFunc func(TStructuredData[THeader, TBody], Log) error) StructuredLogDataHandler {
-	return &tStructuredLogDataHandler[THeader, TBody]{typeName: typeName, handleFunc: handleFunc}
+	return &tStructuredLogDataHandler[THeader, TBody]{typeName: typeName, handleFunc: handleFunc, schema: NewStructureSchema[THeader, TBody]()}
 }
 
 type tStructuredLogDataHandler[THeader, TBody any] struct {
 	handleFunc func(TStructuredData[THeader, TBody], Log) error
 	typeName   string
+	schema     StructureSchema
 }
 
 func (h *tStructuredLogDataHandler[THeader, TBody]) Type() string {
@@ -82,3 +240,12 @@ func (h *tStructuredLogDataHandler[THeader, TBody]) Handle(json string, log Log)
 
 	return h.handleFunc(data, log)
 }
+
+func (h *tStructuredLogDataHandler[THeader, TBody]) HandleStrict(json string, log Log) error {
+	data, err := JSONToStructuredDataStrict[THeader, TBody](h.typeName, h.schema, json)
+	if err != nil {
+		return err
+	}
+
+	return h.handleFunc(data, log)
+}
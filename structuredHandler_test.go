@@ -1,8 +1,11 @@
 package ueloghandler_test
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -141,3 +144,188 @@ func TestStructuredLogHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestStructuredLogHandlerLenient(t *testing.T) {
+	assert := assert.New(t)
+
+	payload := func(structureType string, body string) string {
+		return ueloghandler.BeginStructuredStr + fmt.Sprintf(`{"Meta":{"Type":"%s"},"Body":%s}`, structureType, body) + ueloghandler.EndStructuredStr
+	}
+	logStr := payload("TestStructure", `{"Meta":{},"Body":{"BodyString":"first"}}`) +
+		ueloghandler.BeginStructuredStr + `{"Meta":` + ueloghandler.EndStructuredStr +
+		payload("UnknownStructure", `{}`) +
+		payload("FailStructure", `{"Meta":{},"Body":{}}`) +
+		payload("TestStructure", `{"Meta":{},"Body":{"BodyString":1}}`) +
+		payload("TestStructure", `{"Meta":{},"Body":{"BodyString":"last"}}`)
+
+	var handled []string
+	var malformed, unknown []*ueloghandler.StructuredPayloadError
+	logHandler := ueloghandler.NewStructuredLogHandlerWithConfig(ueloghandler.StructuredLogHandlerConfig{
+		Lenient: true,
+		OnMalformed: func(err *ueloghandler.StructuredPayloadError) {
+			malformed = append(malformed, err)
+		},
+		OnUnknownType: func(err *ueloghandler.StructuredPayloadError) {
+			unknown = append(unknown, err)
+		},
+	})
+	logHandler.AddHandler(ueloghandler.NewStructuredLogDataHandler("TestStructure", func(data ueloghandler.TStructuredData[handlerTestMeta, handlerTestBody], log ueloghandler.Log) error {
+		handled = append(handled, data.Body.BodyString)
+		return nil
+	}))
+	failErr := errors.New("fail")
+	logHandler.AddHandler(ueloghandler.NewStructuredLogDataHandler("FailStructure", func(data ueloghandler.TStructuredData[handlerTestMeta, handlerTestBody], log ueloghandler.Log) error {
+		return failErr
+	}))
+
+	err := logHandler.HandleLog(ueloghandler.Log{Log: logStr})
+	assert.Equal([]string{"first", "last"}, handled)
+
+	if assert.Len(malformed, 2) {
+		assert.Equal(`{"Meta":`, malformed[0].Payload.JSON)
+		assert.Equal(logStr, malformed[0].Log.Log)
+		// Payload which the handler can not decode is also malformed
+		var typeErr *json.UnmarshalTypeError
+		assert.Equal("TestStructure", malformed[1].Type)
+		assert.ErrorAs(malformed[1], &typeErr)
+	}
+
+	assert.Len(unknown, 1)
+	assert.Equal("UnknownStructure", unknown[0].Type)
+	assert.ErrorIs(unknown[0], ueloghandler.ErrUnknownStructureType)
+
+	var payloadErrs ueloghandler.StructuredPayloadErrors
+	assert.ErrorAs(err, &payloadErrs)
+	assert.Len(payloadErrs, 1)
+	assert.Equal("FailStructure", payloadErrs[0].Type)
+	assert.ErrorIs(payloadErrs[0], failErr)
+
+	// Handler errors are passed to OnHandlerError instead of returned
+	var handlerErrs []*ueloghandler.StructuredPayloadError
+	logHandler = ueloghandler.NewStructuredLogHandlerWithConfig(ueloghandler.StructuredLogHandlerConfig{
+		Lenient: true,
+		OnHandlerError: func(err *ueloghandler.StructuredPayloadError) {
+			handlerErrs = append(handlerErrs, err)
+		},
+	})
+	logHandler.AddHandler(ueloghandler.NewStructuredLogDataHandler("FailStructure", func(data ueloghandler.TStructuredData[handlerTestMeta, handlerTestBody], log ueloghandler.Log) error {
+		return failErr
+	}))
+	assert.NoError(logHandler.HandleLog(ueloghandler.Log{Log: logStr}))
+	if assert.Len(handlerErrs, 1) {
+		assert.Equal("FailStructure", handlerErrs[0].Type)
+		assert.ErrorIs(handlerErrs[0], failErr)
+	}
+}
+
+func TestStructuredLogHandlerFallback(t *testing.T) {
+	assert := assert.New(t)
+
+	var fallbackJSON []string
+	logHandler := ueloghandler.NewStructuredLogHandlerWithConfig(ueloghandler.StructuredLogHandlerConfig{
+		Fallback: fallbackDataHandler(func(json string, log ueloghandler.Log) error {
+			fallbackJSON = append(fallbackJSON, json)
+			return nil
+		}),
+	})
+
+	logStr := ueloghandler.BeginStructuredStr + `{"Meta":{"Type":"UnknownStructure"},"Body":{"Value":1}}` + ueloghandler.EndStructuredStr
+	assert.NoError(logHandler.HandleLog(ueloghandler.Log{Log: logStr}))
+	assert.Equal([]string{`{"Value":1}`}, fallbackJSON)
+}
+
+type fallbackDataHandler func(json string, log ueloghandler.Log) error
+
+func (f fallbackDataHandler) Handle(json string, log ueloghandler.Log) error {
+	return f(json, log)
+}
+
+func (f fallbackDataHandler) Type() string {
+	return ""
+}
+
+func TestStructuredLogHandlerInt64(t *testing.T) {
+	assert := assert.New(t)
+
+	type body struct {
+		Int64  int64
+		UInt64 uint64
+	}
+
+	var got body
+	logHandler := ueloghandler.NewStructuredLogHandler()
+	logHandler.AddHandler(ueloghandler.NewStructuredLogDataHandler("Int64Structure", func(data ueloghandler.TStructuredData[struct{}, body], log ueloghandler.Log) error {
+		got = data.Body
+		return nil
+	}))
+
+	logStr := ueloghandler.BeginStructuredStr + `{"Meta":{"Type":"Int64Structure"},"Body":{"Meta":{},"Body":{"Int64":9223372036854775807,"UInt64":18446744073709551615}}}` + ueloghandler.EndStructuredStr
+	assert.NoError(logHandler.HandleLog(ueloghandler.Log{Log: logStr}))
+	assert.Equal(body{Int64: math.MaxInt64, UInt64: math.MaxUint64}, got)
+}
+
+func BenchmarkStructuredLogHandler(b *testing.B) {
+	logHandler := ueloghandler.NewStructuredLogHandler()
+	logHandler.AddHandler(ueloghandler.NewStructuredLogDataHandler("TestStructure", func(data ueloghandler.TStructuredData[handlerTestMeta, handlerTestBody], log ueloghandler.Log) error {
+		return nil
+	}))
+
+	payload := ueloghandler.BeginStructuredStr + `{"Meta":{"Type":"TestStructure"},"Body":{"Meta":{"MetaString":"test1","MetaInt":1,"MetaFloat":1.0},"Body":{"BodyString":"test1","BodyInt":2,"BodyVector":{"X":1.1,"Y":1.2,"Z":1.3}}}}` + ueloghandler.EndStructuredStr
+	for _, payloads := range []int{1, 10} {
+		log := ueloghandler.NewLog("[2022.05.01-17.56.38:615][429]LogTemp: " + strings.Repeat(payload, payloads) + "\n")
+		b.Run(fmt.Sprintf("Payloads%d", payloads), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(log.Log)))
+			for i := 0; i < b.N; i++ {
+				if err := logHandler.HandleLog(log); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkFindStructuredPayloads(b *testing.B) {
+	logStr := "[2022.05.01-17.56.38:615][429]LogTemp: " + ueloghandler.BeginStructuredStr + `{"Meta":{"Type":"TestStructure"},"Body":{"Meta":{},"Body":{"BodyString":"test1","BodyInt":2}}}` + ueloghandler.EndStructuredStr + "\n"
+	b.ReportAllocs()
+	b.SetBytes(int64(len(logStr)))
+	for i := 0; i < b.N; i++ {
+		ueloghandler.FindStructuredPayloads(logStr)
+	}
+}
+
+func TestStructuredLogHandlerStrict(t *testing.T) {
+	assert := assert.New(t)
+
+	logStr := ueloghandler.BeginStructuredStr + `{"Meta":{"Type":"TestStructure"},"Body":{"Meta":{"MetaString":"a","MetaInt":1,"MetaFloat":1},"Body":{"BodyString":"b","BodyInt":2,"BodyVector":{"X":1,"Y":2,"Z":3},"NewField":1}}}` + ueloghandler.EndStructuredStr
+
+	newHandler := func(config ueloghandler.StructuredLogHandlerConfig) *ueloghandler.StructuredLogHandler {
+		logHandler := ueloghandler.NewStructuredLogHandlerWithConfig(config)
+		logHandler.AddHandler(ueloghandler.NewStructuredLogDataHandler("TestStructure", func(data ueloghandler.TStructuredData[handlerTestMeta, handlerTestBody], log ueloghandler.Log) error {
+			return nil
+		}))
+		return logHandler
+	}
+
+	assert.NoError(newHandler(ueloghandler.StructuredLogHandlerConfig{}).HandleLog(ueloghandler.Log{Log: logStr}))
+
+	err := newHandler(ueloghandler.StructuredLogHandlerConfig{Strict: true}).HandleLog(ueloghandler.Log{Log: logStr})
+	var validationErr *ueloghandler.ValidationError
+	if assert.ErrorAs(err, &validationErr) {
+		assert.Equal("TestStructure", validationErr.Type)
+		assert.Equal("Body.NewField", validationErr.Field)
+		assert.Equal("unknown field", validationErr.Reason)
+	}
+
+	var malformed []*ueloghandler.StructuredPayloadError
+	err = newHandler(ueloghandler.StructuredLogHandlerConfig{
+		Strict:  true,
+		Lenient: true,
+		OnMalformed: func(err *ueloghandler.StructuredPayloadError) {
+			malformed = append(malformed, err)
+		},
+	}).HandleLog(ueloghandler.Log{Log: logStr})
+	assert.NoError(err)
+	assert.Len(malformed, 1)
+	assert.ErrorAs(malformed[0], &validationErr)
+}
@@ -0,0 +1,352 @@
+package ueloghandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ValidationError Structured log payload does not match the structure definition
+type ValidationError struct {
+	// Structure type. e.g. Meta.Type of payload
+	Type string
+	// Path of offending field. e.g. Body.Damage
+	Field string
+	// e.g. unknown field, missing field
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid structured log %s: %s: %s", e.Type, e.Field, e.Reason)
+}
+
+// StructureSchema Field names required in Meta and Body
+//
+// Generated handlers use the fields defined in the structuregen schema file.
+type StructureSchema struct {
+	Meta []string
+	Body []string
+}
+
+// NewStructureSchema Create schema requiring all exported fields of TMeta and TBody
+//
+// Field names are taken from json tags if exist and fields of embedded structs are promoted as encoding/json does.
+// Types other than struct do not require any fields.
+func NewStructureSchema[TMeta, TBody any]() StructureSchema {
+	return StructureSchema{
+		Meta: structFieldNames(reflect.TypeOf((*TMeta)(nil)).Elem()),
+		Body: structFieldNames(reflect.TypeOf((*TBody)(nil)).Elem()),
+	}
+}
+
+func structFieldNames(t reflect.Type) []string {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	names := []string{}
+	for _, field := range structFields(t) {
+		names = append(names, field.name)
+	}
+	return names
+}
+
+// jsonField Field of struct encoded in JSON
+type jsonField struct {
+	name   string
+	tagged bool
+	index  []int
+	typ    reflect.Type
+}
+
+// structFields Get fields of t encoded in JSON in order of index
+//
+// Same as encoding/json, fields of embedded structs are promoted and hidden by shallower or tagged fields with the same name.
+// Fields with the same name at the same depth hide each other.
+func structFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	current := []jsonField{}
+	next := []jsonField{{typ: t}}
+	count := map[reflect.Type]int{}
+	nextCount := map[reflect.Type]int{}
+	visited := map[reflect.Type]bool{}
+
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[reflect.Type]int{}
+
+		for _, parent := range current {
+			if visited[parent.typ] {
+				continue
+			}
+			visited[parent.typ] = true
+
+			for i := 0; i < parent.typ.NumField(); i++ {
+				field := parent.typ.Field(i)
+				if field.Anonymous {
+					embedded := field.Type
+					if embedded.Kind() == reflect.Pointer {
+						embedded = embedded.Elem()
+					}
+					// Exported fields of unexported embedded structs are still promoted
+					if !field.IsExported() && embedded.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !field.IsExported() {
+					continue
+				}
+
+				tag := field.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name := strings.Split(tag, ",")[0]
+				index := make([]int, len(parent.index)+1)
+				copy(index, parent.index)
+				index[len(parent.index)] = i
+
+				fieldType := field.Type
+				if fieldType.Name() == "" && fieldType.Kind() == reflect.Pointer {
+					fieldType = fieldType.Elem()
+				}
+
+				if name != "" || !field.Anonymous || fieldType.Kind() != reflect.Struct {
+					tagged := name != ""
+					if name == "" {
+						name = field.Name
+					}
+					fields = append(fields, jsonField{name: name, tagged: tagged, index: index, typ: field.Type})
+					if count[parent.typ] > 1 {
+						// The struct is embedded more than once at this depth, so its fields hide each other
+						fields = append(fields, fields[len(fields)-1])
+					}
+					continue
+				}
+
+				nextCount[fieldType]++
+				if nextCount[fieldType] == 1 {
+					next = append(next, jsonField{name: fieldType.Name(), index: index, typ: fieldType})
+				}
+			}
+		}
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].name != fields[j].name {
+			return fields[i].name < fields[j].name
+		}
+		if len(fields[i].index) != len(fields[j].index) {
+			return len(fields[i].index) < len(fields[j].index)
+		}
+		if fields[i].tagged != fields[j].tagged {
+			return fields[i].tagged
+		}
+		return lessIndex(fields[i].index, fields[j].index)
+	})
+
+	// Keep dominant field of each name
+	dominants := fields[:0]
+	for i := 0; i < len(fields); {
+		j := i + 1
+		for j < len(fields) && fields[j].name == fields[i].name {
+			j++
+		}
+		if j-i == 1 || len(fields[i].index) != len(fields[i+1].index) || fields[i].tagged != fields[i+1].tagged {
+			dominants = append(dominants, fields[i])
+		}
+		i = j
+	}
+
+	sort.Slice(dominants, func(i, j int) bool {
+		return lessIndex(dominants[i].index, dominants[j].index)
+	})
+	return dominants
+}
+
+func lessIndex(a, b []int) bool {
+	for i := range a {
+		if i >= len(b) {
+			return false
+		}
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// lookupField Find field for JSON key
+//
+// Same as encoding/json, an exact match is preferred and otherwise the first field matching case-insensitively is used.
+func lookupField(fields []jsonField, key string) (jsonField, bool) {
+	for _, field := range fields {
+		if field.name == key {
+			return field, true
+		}
+	}
+	for _, field := range fields {
+		if strings.EqualFold(field.name, key) {
+			return field, true
+		}
+	}
+	return jsonField{}, false
+}
+
+// JSONToStructuredDataStrict Convert JSON to structured data rejecting unknown fields and missing fields
+//
+// Fields of nested structs are checked as well. All exported fields of nested structs are required.
+// null is rejected except for pointer, slice and map fields, since it is silently decoded as zero.
+// Mismatches between the payload and the schema are returned as *ValidationError.
+func JSONToStructuredDataStrict[TMeta, TBody any](structureType string, schema StructureSchema, jsonStr string) (TStructuredData[TMeta, TBody], error) {
+	var raw struct {
+		Meta json.RawMessage
+		Body json.RawMessage
+	}
+	if err := decodeStrict(structureType, "", []byte(jsonStr), nil, &raw); err != nil {
+		return TStructuredData[TMeta, TBody]{}, err
+	}
+
+	data := TStructuredData[TMeta, TBody]{}
+	if err := decodeStrictSection(structureType, "Meta", raw.Meta, schema.Meta, &data.Meta); err != nil {
+		return TStructuredData[TMeta, TBody]{}, err
+	}
+	if err := decodeStrictSection(structureType, "Body", raw.Body, schema.Body, &data.Body); err != nil {
+		return TStructuredData[TMeta, TBody]{}, err
+	}
+	return data, nil
+}
+
+func decodeStrictSection(structureType, section string, data json.RawMessage, required []string, v interface{}) error {
+	if len(data) == 0 || string(data) == "null" {
+		return &ValidationError{Type: structureType, Field: section, Reason: "missing field"}
+	}
+	if required == nil {
+		required = []string{}
+	}
+	return decodeStrict(structureType, section, data, required, v)
+}
+
+// decodeStrict Validate fields of data against type of v and decode data into v
+//
+// required is the required field names of v. If nil, all exported fields are required.
+func decodeStrict(structureType, section string, data []byte, required []string, v interface{}) error {
+	if err := validateStrictFields(structureType, section, data, reflect.TypeOf(v).Elem(), required); err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	field := section
+	reason := err.Error()
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		field = joinFieldPath(section, typeErr.Field)
+		reason = fmt.Sprintf("cannot decode %s into %s", typeErr.Value, typeErr.Type)
+	}
+	return &ValidationError{Type: structureType, Field: field, Reason: reason}
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// validateStrictFields Compare keys of JSON objects in data with fields of t recursively
+//
+// null is accepted only for pointers, interfaces, slices and maps.
+// Values which do not match the kind of t are left to the decoder to report type errors.
+func validateStrictFields(structureType, path string, data []byte, t reflect.Type, required []string) error {
+	if string(bytes.TrimSpace(data)) == "null" {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+			return nil
+		default:
+			// encoding/json leaves the value zero. e.g. NaN and infinity written as null by generated C++
+			return &ValidationError{Type: structureType, Field: path, Reason: "null for non-optional field"}
+		}
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return validateStrictFields(structureType, path, data, t.Elem(), nil)
+	case reflect.Slice, reflect.Array:
+		var elems []json.RawMessage
+		if err := json.Unmarshal(data, &elems); err != nil {
+			return nil
+		}
+		for i, elem := range elems {
+			if err := validateStrictFields(structureType, fmt.Sprintf("%s[%d]", path, i), elem, t.Elem(), nil); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil
+		}
+		for _, key := range sortedMapKeys(values) {
+			if err := validateStrictFields(structureType, joinFieldPath(path, key), values[key], t.Elem(), nil); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil
+		}
+		fields := structFields(t)
+		keyFields := map[string]jsonField{}
+		found := map[string]bool{}
+		for _, key := range sortedMapKeys(values) {
+			field, ok := lookupField(fields, key)
+			if !ok {
+				return &ValidationError{Type: structureType, Field: joinFieldPath(path, key), Reason: "unknown field"}
+			}
+			keyFields[key] = field
+			found[field.name] = true
+		}
+		if required == nil {
+			required = structFieldNames(t)
+		}
+		for _, name := range required {
+			if !found[name] {
+				return &ValidationError{Type: structureType, Field: joinFieldPath(path, name), Reason: "missing field"}
+			}
+		}
+		for _, key := range sortedMapKeys(values) {
+			if err := validateStrictFields(structureType, joinFieldPath(path, key), values[key], keyFields[key].typ, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func sortedMapKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinFieldPath(section, field string) string {
+	switch {
+	case section == "":
+		return field
+	case field == "":
+		return section
+	default:
+		return section + "." + field
+	}
+}
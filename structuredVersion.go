@@ -0,0 +1,125 @@
+package ueloghandler
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// StructureChange Change of structure introduced in Version
+type StructureChange struct {
+	Version int
+	// Field name in Version to field name before Version
+	Renames map[string]string
+	// Values of Body fields added in Version. Used for payloads of older versions.
+	Defaults map[string]interface{}
+}
+
+// StructureHistory Current version of structure and changes from older versions
+type StructureHistory struct {
+	Version int
+	Schema  StructureSchema
+	// Meta values of current version. Used for Meta fields missing in payloads of older versions.
+	Meta map[string]interface{}
+	// Changes in ascending order of Version
+	Changes []StructureChange
+}
+
+// VersionedStructuredLogDataHandler Handler accepting payloads of older structure versions
+//
+// StructuredLogHandler calls Upgrade before Handle when Meta.Version of the payload differs from Version.
+// Payloads without Meta.Version are version 1.
+type VersionedStructuredLogDataHandler interface {
+	StructuredLogDataHandler
+	Version() int
+	// Upgrade Convert json of version to json of current version
+	Upgrade(version int, json string) (string, error)
+}
+
+// UpgradeStructuredJSON Convert json of version to current version of history
+//
+// Renames and Defaults of the changes newer than version are applied in order.
+// Fields which are not in Schema are removed so that the result passes strict decoding,
+// and missing Meta fields are filled with the current values.
+// Payloads of versions newer than history are rejected with *ValidationError.
+func UpgradeStructuredJSON(structureType string, history StructureHistory, version int, jsonStr string) (string, error) {
+	if version == history.Version {
+		return jsonStr, nil
+	}
+	if version > history.Version || version < 1 {
+		return "", &ValidationError{Type: structureType, Field: "Meta.Version", Reason: fmt.Sprintf("unsupported version %d", version)}
+	}
+
+	var data struct {
+		Meta map[string]json.RawMessage
+		Body map[string]json.RawMessage
+	}
+	if err := json.Unmarshal([]byte(jsonStr), &data); err != nil {
+		return "", err
+	}
+	if data.Meta == nil {
+		data.Meta = map[string]json.RawMessage{}
+	}
+	if data.Body == nil {
+		data.Body = map[string]json.RawMessage{}
+	}
+
+	for _, change := range history.Changes {
+		if change.Version <= version {
+			continue
+		}
+		// Renames are applied from the body before the change, so that swapped names are not overwritten
+		body := make(map[string]json.RawMessage, len(data.Body))
+		for name, value := range data.Body {
+			body[name] = value
+		}
+		for _, oldName := range change.Renames {
+			delete(data.Body, oldName)
+		}
+		for name, oldName := range change.Renames {
+			if value, ok := body[oldName]; ok {
+				data.Body[name] = value
+			}
+		}
+		for name, value := range change.Defaults {
+			if _, ok := data.Body[name]; ok {
+				continue
+			}
+			raw, err := json.Marshal(value)
+			if err != nil {
+				return "", err
+			}
+			data.Body[name] = raw
+		}
+	}
+
+	for name, value := range history.Meta {
+		if _, ok := data.Meta[name]; ok {
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		data.Meta[name] = raw
+	}
+	removeUnknownFields(data.Meta, history.Schema.Meta)
+	removeUnknownFields(data.Body, history.Schema.Body)
+
+	upgraded, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(upgraded), nil
+}
+
+func removeUnknownFields(fields map[string]json.RawMessage, names []string) {
+	known := make(map[string]bool, len(names))
+	for _, name := range names {
+		known[name] = true
+	}
+	for name := range fields {
+		if !known[name] {
+			delete(fields, name)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package ueloghandler_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+var versionTestHistory = ueloghandler.StructureHistory{
+	Version: 3,
+	Schema: ueloghandler.StructureSchema{
+		Meta: []string{"MetaFloat", "MetaInt", "MetaString"},
+		Body: []string{"BodyInt", "BodyString", "BodyVector"},
+	},
+	Meta: map[string]interface{}{"MetaString": "meta", "MetaInt": 1, "MetaFloat": 1.5},
+	Changes: []ueloghandler.StructureChange{
+		// BodyInt renamed from Count. BodyString added.
+		{Version: 2, Renames: map[string]string{"BodyInt": "Count"}, Defaults: map[string]interface{}{"BodyString": "default"}},
+		// BodyVector added. Removed is dropped.
+		{Version: 3, Defaults: map[string]interface{}{"BodyVector": map[string]interface{}{"X": 1, "Y": 2, "Z": 3}}},
+	},
+}
+
+func TestUpgradeStructuredJSON(t *testing.T) {
+	type testCase struct {
+		version  int
+		jsonStr  string
+		wantJSON string
+		wantErr  bool
+	}
+	testCases := []testCase{
+		{
+			version:  1,
+			jsonStr:  `{"Meta":{"MetaString":"a"},"Body":{"Count":10,"Removed":true}}`,
+			wantJSON: `{"Meta":{"MetaFloat":1.5,"MetaInt":1,"MetaString":"a"},"Body":{"BodyInt":10,"BodyString":"default","BodyVector":{"X":1,"Y":2,"Z":3}}}`,
+		},
+		{
+			version:  2,
+			jsonStr:  `{"Meta":{"MetaString":"a","MetaInt":2,"MetaFloat":0},"Body":{"BodyInt":10,"BodyString":"b"}}`,
+			wantJSON: `{"Meta":{"MetaFloat":0,"MetaInt":2,"MetaString":"a"},"Body":{"BodyInt":10,"BodyString":"b","BodyVector":{"X":1,"Y":2,"Z":3}}}`,
+		},
+		{
+			version:  3,
+			jsonStr:  `{"Meta":{},"Body":{"Unknown":1}}`,
+			wantJSON: `{"Meta":{},"Body":{"Unknown":1}}`,
+		},
+		{
+			version: 4,
+			jsonStr: `{"Meta":{},"Body":{}}`,
+			wantErr: true,
+		},
+	}
+
+	for i := range testCases {
+		testCase := testCases[i]
+		t.Run(fmt.Sprintf("Case%d", i), func(t *testing.T) {
+			assert := assert.New(t)
+			upgraded, err := ueloghandler.UpgradeStructuredJSON("Versioned", versionTestHistory, testCase.version, testCase.jsonStr)
+			if testCase.wantErr {
+				var validationErr *ueloghandler.ValidationError
+				if assert.ErrorAs(err, &validationErr) {
+					assert.Equal("Meta.Version", validationErr.Field)
+				}
+				return
+			}
+			assert.NoError(err)
+			assert.JSONEq(testCase.wantJSON, upgraded)
+		})
+	}
+}
+
+func TestUpgradeStructuredJSONSwap(t *testing.T) {
+	assert := assert.New(t)
+
+	history := ueloghandler.StructureHistory{
+		Version: 2,
+		Schema:  ueloghandler.StructureSchema{Body: []string{"From", "To", "Value"}},
+		Changes: []ueloghandler.StructureChange{
+			// From and To are swapped, and Value is renamed from From
+			{Version: 2, Renames: map[string]string{"From": "To", "To": "From", "Value": "From"}},
+		},
+	}
+	upgraded, err := ueloghandler.UpgradeStructuredJSON("Swap", history, 1, `{"Meta":{},"Body":{"From":"a","To":"b"}}`)
+	assert.NoError(err)
+	assert.JSONEq(`{"Meta":{},"Body":{"From":"b","To":"a","Value":"a"}}`, upgraded)
+}
+
+// versionedTestHandler Handler implementing HandleStrict like generated handlers
+type versionedTestHandler struct {
+	ueloghandler.StrictStructuredLogDataHandler
+}
+
+func (h *versionedTestHandler) Version() int {
+	return versionTestHistory.Version
+}
+
+func (h *versionedTestHandler) Upgrade(version int, json string) (string, error) {
+	return ueloghandler.UpgradeStructuredJSON(h.Type(), versionTestHistory, version, json)
+}
+
+func TestStructuredLogHandlerVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	var actual []handlerTestStructureData
+	logHandler := ueloghandler.NewStructuredLogHandlerWithConfig(ueloghandler.StructuredLogHandlerConfig{Strict: true})
+	dataHandler := ueloghandler.NewStructuredLogDataHandler("Versioned", func(data ueloghandler.TStructuredData[handlerTestMeta, handlerTestBody], log ueloghandler.Log) error {
+		actual = append(actual, handlerTestStructureData(data))
+		return nil
+	})
+	assert.NoError(logHandler.AddHandler(&versionedTestHandler{dataHandler.(ueloghandler.StrictStructuredLogDataHandler)}))
+
+	payload := func(header, body string) string {
+		return ueloghandler.BeginStructuredStr + fmt.Sprintf(`{"Meta":%s,"Body":%s}`, header, body) + ueloghandler.EndStructuredStr
+	}
+	logs := []string{
+		// Payload generated before versioning is version 1
+		payload(`{"Type":"Versioned"}`, `{"Meta":{"MetaString":"v1"},"Body":{"Count":1}}`),
+		payload(`{"Type":"Versioned","Version":2}`, `{"Meta":{"MetaString":"v2","MetaInt":2,"MetaFloat":2},"Body":{"BodyInt":2,"BodyString":"b"}}`),
+		payload(`{"Type":"Versioned","Version":3}`, `{"Meta":{"MetaString":"v3","MetaInt":3,"MetaFloat":3},"Body":{"BodyInt":3,"BodyString":"c","BodyVector":{"X":0,"Y":0,"Z":0}}}`),
+	}
+	for _, log := range logs {
+		assert.NoError(logHandler.HandleLog(ueloghandler.Log{Log: log}))
+	}
+
+	want := []handlerTestStructureData{
+		{Meta: handlerTestMeta{MetaString: "v1", MetaInt: 1, MetaFloat: 1.5}, Body: handlerTestBody{BodyInt: 1, BodyString: "default", BodyVector: handlerTestVector{X: 1, Y: 2, Z: 3}}},
+		{Meta: handlerTestMeta{MetaString: "v2", MetaInt: 2, MetaFloat: 2}, Body: handlerTestBody{BodyInt: 2, BodyString: "b", BodyVector: handlerTestVector{X: 1, Y: 2, Z: 3}}},
+		{Meta: handlerTestMeta{MetaString: "v3", MetaInt: 3, MetaFloat: 3}, Body: handlerTestBody{BodyInt: 3, BodyString: "c"}},
+	}
+	assert.Equal(want, actual)
+
+	err := logHandler.HandleLog(ueloghandler.Log{Log: payload(`{"Type":"Versioned","Version":4}`, `{"Meta":{},"Body":{}}`)})
+	var validationErr *ueloghandler.ValidationError
+	assert.ErrorAs(err, &validationErr)
+
+	// Upgraded payload is decoded strictly
+	err = logHandler.HandleLog(ueloghandler.Log{Log: payload(`{"Type":"Versioned","Version":3}`, `{"Meta":{"MetaString":"v3","MetaInt":3,"MetaFloat":3},"Body":{"BodyInt":3,"BodyString":"c"}}`)})
+	if assert.ErrorAs(err, &validationErr) {
+		assert.Equal("Body.BodyVector", validationErr.Field)
+	}
+}
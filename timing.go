@@ -0,0 +1,194 @@
+package ueloghandler
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// TimingKind Kind of startup and loading timing
+type TimingKind string
+
+const (
+	// Total time of engine initialization
+	TimingKindEngineInit TimingKind = "EngineInit"
+	// Time of initialization steps reported by LogInit
+	TimingKindInit TimingKind = "Init"
+	// Time to load map. Name is the map.
+	TimingKindMapLoad TimingKind = "MapLoad"
+	// Number of shaders waiting for compilation
+	TimingKindShaderCompile TimingKind = "ShaderCompile"
+	// Time of asset registry scan
+	TimingKindAssetRegistryScan TimingKind = "AssetRegistryScan"
+)
+
+// TimingRule Extract timing of Kind from logs matching Pattern
+//
+// Pattern can have the following named submatches.
+//
+//	seconds: Time in seconds
+//	count: Number of items. Digit separators are ignored.
+//	name: Name of what is measured. e.g. map name
+type TimingRule struct {
+	Kind    TimingKind
+	Pattern *regexp.Regexp
+}
+
+// DefaultTimingRules Rules for timings printed by Unreal Engine
+var DefaultTimingRules = []TimingRule{
+	{TimingKindEngineInit, regexp.MustCompile(`LogLoad: \(Engine Initialization\) Total time: (?P<seconds>[\d.]+) seconds`)},
+	{TimingKindInit, regexp.MustCompile(`LogInit: (?:Display: )?Took (?P<seconds>[\d.]+) ?s(?:econds)? to (?P<name>[^\r\n]+?)\.?$`)},
+	{TimingKindMapLoad, regexp.MustCompile(`LogLoad: Took (?P<seconds>[\d.]+) seconds to LoadMap\((?P<name>[^)]*)\)`)},
+	{TimingKindShaderCompile, regexp.MustCompile(`LogShaderCompilers: (?:Display: )?Shaders left to compile (?P<count>[\d,]+)`)},
+	{TimingKindAssetRegistryScan, regexp.MustCompile(`LogAssetRegistry: (?:Display: )?.*?(?:[Ss]earch completed in|[Ss]can (?:took|completed in)) (?P<seconds>[\d.]+) ?s`)},
+}
+
+// TimingEvent Timing extracted from log
+type TimingEvent struct {
+	Kind    TimingKind
+	Name    string
+	Seconds float64
+	Count   int64
+	Time    string
+	Frame   string
+	// Session given by SessionLogHandler
+	Session int
+}
+
+// TimingSummary Timings of the same kind and name
+type TimingSummary struct {
+	Kind         TimingKind
+	Name         string
+	Events       int
+	TotalSeconds float64
+	MaxSeconds   float64
+	MaxCount     int64
+}
+
+// TimingReport Summary of startup and loading timings
+type TimingReport struct {
+	Events    []TimingEvent
+	Summaries []TimingSummary
+}
+
+type TimingConfig struct {
+	// Default is DefaultTimingRules.
+	Rules []TimingRule
+	// Called for each extracted timing
+	OnEvent func(event TimingEvent) error
+}
+
+// TimingLogHandler Extract startup and loading timings from logs
+//
+// Call Report to get the timings after handling logs.
+// The report can be compared across runs to track startup regressions.
+type TimingLogHandler struct {
+	config TimingConfig
+	mu     sync.Mutex
+	events []TimingEvent
+}
+
+func NewTimingLogHandler(config TimingConfig) *TimingLogHandler {
+	if config.Rules == nil {
+		config.Rules = DefaultTimingRules
+	}
+	return &TimingLogHandler{config: config}
+}
+
+func (h *TimingLogHandler) HandleLog(log Log) error {
+	return h.HandleSessionLog(log, 0, SessionPhaseNone)
+}
+
+func (h *TimingLogHandler) HandleSessionLog(log Log, session int, phase SessionPhase) error {
+	for _, rule := range h.config.Rules {
+		event, ok := rule.extract(log, session)
+		if !ok {
+			continue
+		}
+
+		h.mu.Lock()
+		h.events = append(h.events, event)
+		h.mu.Unlock()
+
+		if h.config.OnEvent != nil {
+			if err := h.config.OnEvent(event); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *TimingRule) extract(log Log, session int) (TimingEvent, bool) {
+	matches := r.Pattern.FindStringSubmatch(strings.SplitN(log.Log, "\n", 2)[0])
+	if matches == nil {
+		return TimingEvent{}, false
+	}
+
+	event := TimingEvent{Kind: r.Kind, Time: log.Time, Frame: strings.TrimSpace(log.Frame), Session: session}
+	for i, name := range r.Pattern.SubexpNames() {
+		value := strings.TrimSpace(matches[i])
+		switch name {
+		case "seconds":
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return TimingEvent{}, false
+			}
+			event.Seconds = seconds
+		case "count":
+			count, err := strconv.ParseInt(strings.ReplaceAll(value, ",", ""), 10, 64)
+			if err != nil {
+				return TimingEvent{}, false
+			}
+			event.Count = count
+		case "name":
+			event.Name = value
+		}
+	}
+	return event, true
+}
+
+// Report Get extracted timings and their summaries sorted by kind and name
+func (h *TimingLogHandler) Report() TimingReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	report := TimingReport{Events: append([]TimingEvent{}, h.events...), Summaries: []TimingSummary{}}
+
+	type summaryKey struct {
+		kind TimingKind
+		name string
+	}
+	summaries := map[summaryKey]*TimingSummary{}
+	for _, event := range h.events {
+		key := summaryKey{kind: event.Kind, name: event.Name}
+		summary, ok := summaries[key]
+		if !ok {
+			summary = &TimingSummary{Kind: event.Kind, Name: event.Name}
+			summaries[key] = summary
+		}
+		summary.Events++
+		summary.TotalSeconds += event.Seconds
+		if event.Seconds > summary.MaxSeconds {
+			summary.MaxSeconds = event.Seconds
+		}
+		if event.Count > summary.MaxCount {
+			summary.MaxCount = event.Count
+		}
+	}
+
+	for _, summary := range summaries {
+		report.Summaries = append(report.Summaries, *summary)
+	}
+	sort.Slice(report.Summaries, func(i, j int) bool {
+		a, b := report.Summaries[i], report.Summaries[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+
+	return report
+}
@@ -0,0 +1,59 @@
+package ueloghandler_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ueloghandler "github.com/y-akahori-ramen/ueLogHandler"
+)
+
+func TestTimingLogHandler(t *testing.T) {
+	testLog := `Log file open, 05/02/22 13:01:53
+[2022.05.02-04.01.53:149][  0]LogInit: Display: Took 1.25 seconds to initialize RHI.
+[2022.05.02-04.01.54:000][  0]LogAssetRegistry: Asset discovery search completed in 3.5 seconds
+[2022.05.02-04.01.55:000][  0]LogShaderCompilers: Display: Shaders left to compile 1,234
+[2022.05.02-04.01.56:000][  0]LogShaderCompilers: Display: Shaders left to compile 200
+[2022.05.02-04.01.57:000][  0]LogLoad: Took 2.5 seconds to LoadMap(/Game/Maps/Entry)
+[2022.05.02-04.01.58:000][  0]LogLoad: (Engine Initialization) Total time: 12.75 seconds
+[2022.05.02-04.01.59:000][ 10]LogLoad: Took 0.5 seconds to LoadMap(/Game/Maps/Entry)
+[2022.05.02-04.02.00:000][ 20]LogLoad: Took 1.5 seconds to LoadMap(/Game/Maps/Map01)
+`
+	assert := assert.New(t)
+
+	var events []ueloghandler.TimingEvent
+	handler := ueloghandler.NewTimingLogHandler(ueloghandler.TimingConfig{
+		OnEvent: func(event ueloghandler.TimingEvent) error {
+			events = append(events, event)
+			return nil
+		},
+	})
+
+	logs, err := ueloghandler.ReadLogs(strings.NewReader(testLog))
+	assert.NoError(err)
+	for _, log := range logs {
+		assert.NoError(handler.HandleLog(log))
+	}
+
+	report := handler.Report()
+	assert.Equal(events, report.Events)
+	assert.Equal([]ueloghandler.TimingEvent{
+		{Kind: ueloghandler.TimingKindInit, Name: "initialize RHI", Seconds: 1.25, Time: "2022.05.02-04.01.53:149", Frame: "0"},
+		{Kind: ueloghandler.TimingKindAssetRegistryScan, Seconds: 3.5, Time: "2022.05.02-04.01.54:000", Frame: "0"},
+		{Kind: ueloghandler.TimingKindShaderCompile, Count: 1234, Time: "2022.05.02-04.01.55:000", Frame: "0"},
+		{Kind: ueloghandler.TimingKindShaderCompile, Count: 200, Time: "2022.05.02-04.01.56:000", Frame: "0"},
+		{Kind: ueloghandler.TimingKindMapLoad, Name: "/Game/Maps/Entry", Seconds: 2.5, Time: "2022.05.02-04.01.57:000", Frame: "0"},
+		{Kind: ueloghandler.TimingKindEngineInit, Seconds: 12.75, Time: "2022.05.02-04.01.58:000", Frame: "0"},
+		{Kind: ueloghandler.TimingKindMapLoad, Name: "/Game/Maps/Entry", Seconds: 0.5, Time: "2022.05.02-04.01.59:000", Frame: "10"},
+		{Kind: ueloghandler.TimingKindMapLoad, Name: "/Game/Maps/Map01", Seconds: 1.5, Time: "2022.05.02-04.02.00:000", Frame: "20"},
+	}, report.Events)
+
+	assert.Equal([]ueloghandler.TimingSummary{
+		{Kind: ueloghandler.TimingKindAssetRegistryScan, Events: 1, TotalSeconds: 3.5, MaxSeconds: 3.5},
+		{Kind: ueloghandler.TimingKindEngineInit, Events: 1, TotalSeconds: 12.75, MaxSeconds: 12.75},
+		{Kind: ueloghandler.TimingKindInit, Name: "initialize RHI", Events: 1, TotalSeconds: 1.25, MaxSeconds: 1.25},
+		{Kind: ueloghandler.TimingKindMapLoad, Name: "/Game/Maps/Entry", Events: 2, TotalSeconds: 3, MaxSeconds: 2.5},
+		{Kind: ueloghandler.TimingKindMapLoad, Name: "/Game/Maps/Map01", Events: 1, TotalSeconds: 1.5, MaxSeconds: 1.5},
+		{Kind: ueloghandler.TimingKindShaderCompile, Events: 2, MaxCount: 1234},
+	}, report.Summaries)
+}
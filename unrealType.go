@@ -10,3 +10,23 @@ type FVector2D struct {
 	X float64
 	Y float64
 }
+
+type FRotator struct {
+	Pitch float64
+	Yaw   float64
+	Roll  float64
+}
+
+type FQuat struct {
+	X float64
+	Y float64
+	Z float64
+	W float64
+}
+
+type FLinearColor struct {
+	R float64
+	G float64
+	B float64
+	A float64
+}
@@ -2,6 +2,7 @@ package ueloghandler
 
 import (
 	"context"
+	"io"
 	"sync"
 )
 
@@ -21,6 +22,11 @@ func (w *Watcher) AddLogHandler(handler LogHandler) {
 func (w *Watcher) Watch(ctx context.Context, notifier Notifier) error {
 	eventHandleResult := make(chan error)
 
+	source := ""
+	if sourceNotifier, ok := notifier.(SourceNotifier); ok {
+		source = sourceNotifier.Source()
+	}
+
 	var wg sync.WaitGroup
 	watchEnd := make(chan struct{})
 
@@ -31,6 +37,7 @@ func (w *Watcher) Watch(ctx context.Context, notifier Notifier) error {
 			select {
 			case logStr := <-notifier.Logs():
 				log := NewLog(logStr)
+				log.Source = source
 				err := w.handleLog(log)
 				if err != nil {
 					eventHandleResult <- err
@@ -58,6 +65,18 @@ func (w *Watcher) Watch(ctx context.Context, notifier Notifier) error {
 	return err
 }
 
+// Read Handle all logs read from r synchronously
+func (w *Watcher) Read(r io.Reader) error {
+	reader := NewReader(r)
+	for reader.Next() {
+		err := w.handleLog(reader.Log())
+		if err != nil {
+			return err
+		}
+	}
+	return reader.Err()
+}
+
 func (w *Watcher) handleLog(log Log) error {
 	for _, handler := range w.handlerList {
 		err := handler.HandleLog(log)
@@ -184,3 +184,30 @@ func TestWatcher(t *testing.T) {
 		t.Run(fmt.Sprintf("Case%d", i), testCase.Run)
 	}
 }
+
+type TestSourceNotifier struct {
+	*TestNotifier
+}
+
+func (t *TestSourceNotifier) Source() string {
+	return "ue.log"
+}
+
+func TestWatcherSource(t *testing.T) {
+	assert := assert.New(t)
+
+	sources := []string{}
+	watcher := ueloghandler.NewWatcher()
+	watcher.AddLogHandler(ueloghandler.NewLogHandler(func(log ueloghandler.Log) error {
+		sources = append(sources, log.Source)
+		return nil
+	}))
+
+	logs := []string{"LogTemp: Log1\n", "LogTemp: Log2\n"}
+	assert.NoError(watcher.Watch(context.Background(), NewTestNotifier(logs, time.Millisecond, nil)))
+	assert.Equal([]string{"", ""}, sources)
+
+	sources = []string{}
+	assert.NoError(watcher.Watch(context.Background(), &TestSourceNotifier{NewTestNotifier(logs, time.Millisecond, nil)}))
+	assert.Equal([]string{"ue.log", "ue.log"}, sources)
+}